@@ -74,3 +74,45 @@ func (e *HeaderMismatchErr) Error() string {
 func (e *HeaderMismatchErr) AsMap() map[string]any {
 	return structs.ToMap(e, structs.ExportPrivate)
 }
+
+type RowWidthErr struct {
+	Width  int
+	Rows   []int
+	Widths []int
+}
+
+func (e *RowWidthErr) Error() string {
+	return fmt.Sprintf("%d rows do not match the header width of %d;rows:%+v;widths:%+v", len(e.Rows), e.Width, e.Rows, e.Widths)
+}
+
+func (e *RowWidthErr) AsMap() map[string]any {
+	return structs.ToMap(e, structs.ExportPrivate)
+}
+
+type CellParseErr struct {
+	Column string
+	Rows   []int
+	Values []string
+}
+
+func (e *CellParseErr) Error() string {
+	return fmt.Sprintf("%d cells of column %s could not be parsed;rows:%+v;values:%+v", len(e.Rows), e.Column, e.Rows, e.Values)
+}
+
+func (e *CellParseErr) AsMap() map[string]any {
+	return structs.ToMap(e, structs.ExportPrivate)
+}
+
+type SheetNotFoundErr struct {
+	File      string
+	Sheet     string
+	Available []string
+}
+
+func (e *SheetNotFoundErr) Error() string {
+	return fmt.Sprintf("sheet %s was not found in %s;available:%+v", e.Sheet, e.File, e.Available)
+}
+
+func (e *SheetNotFoundErr) AsMap() map[string]any {
+	return structs.ToMap(e, structs.ExportPrivate)
+}
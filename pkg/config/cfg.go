@@ -1,6 +1,8 @@
 package config
 
 import (
+	"bytes"
+	"io"
 	"os"
 
 	"gopkg.in/yaml.v3"
@@ -18,14 +20,23 @@ type Config[B any] struct {
 type ConfigOpt[B any, E any] func(*Config[B])
 
 func NewConfig[B any](path string) (*Config[B], error) {
-	base := new(B)
 	f, err := os.Open(path)
 	if err != nil {
 		return nil, err
 	}
+	defer f.Close()
+	return NewConfigFromReader[B](f)
+}
 
-	dec := yaml.NewDecoder(f)
-	if err = dec.Decode(base); err != nil {
+// NewConfigFromReader decodes the yaml configuration from r without touching the filesystem; works with embed.FS files
+// the environment is overlaid on the decoded values; see EnvPrefix and UnsetEnvErr
+func NewConfigFromReader[B any](r io.Reader) (*Config[B], error) {
+	base := new(B)
+	dec := yaml.NewDecoder(r)
+	if err := dec.Decode(base); err != nil {
+		return nil, err
+	}
+	if err := applyEnv(base); err != nil {
 		return nil, err
 	}
 
@@ -33,3 +44,8 @@ func NewConfig[B any](path string) (*Config[B], error) {
 	config.Base = *base
 	return config, nil
 }
+
+// NewConfigFromBytes decodes the yaml configuration held in b
+func NewConfigFromBytes[B any](b []byte) (*Config[B], error) {
+	return NewConfigFromReader[B](bytes.NewReader(b))
+}
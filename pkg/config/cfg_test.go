@@ -1 +1,197 @@
 package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testBase struct {
+	Service struct {
+		Name string `yaml:"name"`
+		Port int    `yaml:"port"`
+	} `yaml:"service"`
+}
+
+func TestNewConfigFromBytes(t *testing.T) {
+	cfg, err := NewConfigFromBytes[testBase]([]byte("service:\n  name: boiler\n  port: 8080\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "boiler", cfg.Base.Service.Name)
+	assert.Equal(t, 8080, cfg.Base.Service.Port)
+}
+
+func TestNewConfigFromBytesEnv(t *testing.T) {
+	type source struct {
+		Location string `yaml:"location"`
+		Auth     struct {
+			Password string `yaml:"password"`
+		} `yaml:"auth"`
+	}
+	type base struct {
+		Service struct {
+			Name string `yaml:"name"`
+			Port int    `yaml:"port"`
+		} `yaml:"service"`
+		Sources []source `yaml:"sources"`
+	}
+	yml := []byte("service:\n  name: boiler\n  port: 8080\nsources:\n  - location: db-${PLANT}:1433\n    auth:\n      password: committed\n")
+
+	t.Setenv("PLANT", "sofia")
+	t.Setenv("BOILER_SOURCES_0_AUTH_PASSWORD", "secret")
+	t.Setenv("BOILER_SERVICE_PORT", "9090")
+	cfg, err := NewConfigFromBytes[base](yml)
+	require.NoError(t, err)
+	assert.Equal(t, "db-sofia:1433", cfg.Base.Sources[0].Location)
+	assert.Equal(t, "secret", cfg.Base.Sources[0].Auth.Password)
+	assert.Equal(t, 9090, cfg.Base.Service.Port)
+
+	_, err = NewConfigFromBytes[base]([]byte("service:\n  name: ${MISSING_B}-${MISSING_A}\n"))
+	var unset *UnsetEnvErr
+	require.ErrorAs(t, err, &unset)
+	assert.Equal(t, []string{"MISSING_A", "MISSING_B"}, unset.Names)
+}
+
+func TestNewConfigFromBytesEnvDurationAndMaps(t *testing.T) {
+	type base struct {
+		Timeout time.Duration     `yaml:"timeout"`
+		Labels  map[string]string `yaml:"labels"`
+		Extra   map[string]any    `yaml:"extra"`
+	}
+	yml := []byte("timeout: 5s\nlabels:\n  plant: ${PLANT}\nextra:\n  retries: 3\n  db:\n    host: localhost\n")
+
+	t.Setenv("PLANT", "sofia")
+	t.Setenv("BOILER_TIMEOUT", "1m30s")
+	t.Setenv("BOILER_EXTRA_RETRIES", "5")
+	t.Setenv("BOILER_EXTRA_DB_HOST", "db-sofia")
+	cfg, err := NewConfigFromBytes[base](yml)
+	require.NoError(t, err)
+	assert.Equal(t, 90*time.Second, cfg.Base.Timeout)
+	assert.Equal(t, map[string]string{"plant": "sofia"}, cfg.Base.Labels)
+	assert.Equal(t, map[string]any{"retries": 5, "db": map[string]any{"host": "db-sofia"}}, cfg.Base.Extra)
+
+	t.Setenv("BOILER_TIMEOUT", "30")
+	_, err = NewConfigFromBytes[base](yml)
+	assert.ErrorContains(t, err, "BOILER_TIMEOUT")
+}
+
+type validatedBase struct {
+	Service struct {
+		Name string `yaml:"name"`
+	} `yaml:"service"`
+	Sources []struct {
+		Type     string `yaml:"type"`
+		Location string `yaml:"location"`
+	} `yaml:"sources"`
+}
+
+func (b *validatedBase) Validate() error {
+	errs := new(ValidationErr)
+	if len(b.Service.Name) == 0 {
+		errs.Add("service.name", "is required")
+	}
+	for idx, s := range b.Sources {
+		if len(s.Location) == 0 {
+			errs.Add(fmt.Sprintf("sources[%d].location", idx), "is required")
+		}
+	}
+	return errs.OrNil()
+}
+
+func TestNewConfigStrictFromBytes(t *testing.T) {
+	_, err := NewConfigStrictFromBytes[validatedBase]([]byte("service:\n  name: boiler\nsources:\n  - type: sql\n    location: db:1433\n"))
+	require.NoError(t, err)
+
+	_, err = NewConfigStrictFromBytes[validatedBase]([]byte("sources:\n  - type: sql\n"))
+	var invalid *ValidationErr
+	require.ErrorAs(t, err, &invalid)
+	assert.Equal(t, []FieldErr{{Path: "service.name", Problem: "is required"}, {Path: "sources[0].location", Problem: "is required"}}, invalid.Fields)
+}
+
+func TestReloadableWatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cfg.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("service:\n  name: first\n"), 0o644))
+	w := watchReloadable(t, path)
+
+	require.NoError(t, os.WriteFile(path, []byte("service:\n  name: \"\"\n"), 0o644))
+	select {
+	case err := <-w.errs:
+		var invalid *ValidationErr
+		assert.ErrorAs(t, err, &invalid)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the failed reload was not reported")
+	}
+	assert.Equal(t, "first", w.r.Provide().Base.Service.Name)
+
+	require.NoError(t, os.WriteFile(path, []byte("service:\n  name: second\n"), 0o644))
+	assert.Equal(t, "second", w.next(t).Base.Service.Name)
+	assert.Equal(t, "second", w.r.Provide().Base.Service.Name)
+}
+
+func TestReloadableWatchSymlinkSwap(t *testing.T) {
+	// the layout of a Kubernetes ConfigMap volume: cfg.yaml -> ..data/cfg.yaml and ..data -> the current version directory
+	dir := t.TempDir()
+	for version, name := range map[string]string{"v1": "first", "v2": "second"} {
+		require.NoError(t, os.Mkdir(filepath.Join(dir, version), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, version, "cfg.yaml"), []byte("service:\n  name: "+name+"\n"), 0o644))
+	}
+	require.NoError(t, os.Symlink("v1", filepath.Join(dir, "..data")))
+	path := filepath.Join(dir, "cfg.yaml")
+	require.NoError(t, os.Symlink(filepath.Join("..data", "cfg.yaml"), path))
+	w := watchReloadable(t, path)
+
+	require.NoError(t, os.Symlink("v2", filepath.Join(dir, "..data_tmp")))
+	require.NoError(t, os.Rename(filepath.Join(dir, "..data_tmp"), filepath.Join(dir, "..data")))
+	assert.Equal(t, "second", w.next(t).Base.Service.Name)
+}
+
+type reloadableWatch struct {
+	r       *Reloadable[validatedBase]
+	changed chan *Config[validatedBase]
+	errs    chan error
+}
+
+// watchReloadable runs Watch on the configuration at path until the test ends
+func watchReloadable(t *testing.T, path string) *reloadableWatch {
+	t.Helper()
+	w := &reloadableWatch{changed: make(chan *Config[validatedBase], 1), errs: make(chan error, 1)}
+	r, err := NewReloadable[validatedBase](path, WithReloadErrors(func(err error) {
+		select {
+		case w.errs <- err:
+		default:
+		}
+	}))
+	require.NoError(t, err)
+	w.r = r
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- r.Watch(ctx, func(c *Config[validatedBase]) {
+			select {
+			case w.changed <- c:
+			default:
+			}
+		})
+	}()
+	t.Cleanup(func() {
+		cancel()
+		assert.NoError(t, <-done)
+	})
+	return w
+}
+
+func (w *reloadableWatch) next(t *testing.T) *Config[validatedBase] {
+	t.Helper()
+	select {
+	case c := <-w.changed:
+		return c
+	case <-time.After(5 * time.Second):
+		t.Fatal("configuration was not reloaded")
+		return nil
+	}
+}
@@ -0,0 +1,151 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EnvPrefix starts the names of the environment variables overriding configuration fields, e.g. BOILER_SOURCES_0_AUTH_PASSWORD
+const EnvPrefix = "BOILER"
+
+// UnsetEnvErr lists the environment variables referenced by ${NAME} placeholders that are not set
+type UnsetEnvErr struct {
+	Names []string
+}
+
+func (e *UnsetEnvErr) Error() string {
+	return fmt.Sprintf("configuration references unset environment variables:%+v", e.Names)
+}
+
+var placeholder = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// applyEnv overlays the environment on the decoded configuration v: ${NAME} placeholders in string fields are replaced by
+// the value of NAME and then every string, bool, numeric or time.Duration (e.g. "30s") field whose variable is set takes its value.
+// A field's variable is EnvPrefix followed by the yaml keys (the field names if untagged), map keys and slice indices on the way
+// to it, upper cased and joined by "_". Maps with string keys and interface values, e.g. map[string]any, are walked too, but only
+// the entries present in the file can be overridden; the environment cannot add map entries or slice elements
+func applyEnv(v any) error {
+	unset := make([]string, 0)
+	if err := overlay(reflect.ValueOf(v).Elem(), EnvPrefix, &unset); err != nil {
+		return err
+	}
+	if len(unset) > 0 {
+		slices.Sort(unset)
+		return &UnsetEnvErr{Names: slices.Compact(unset)}
+	}
+	return nil
+}
+
+func overlay(v reflect.Value, name string, unset *[]string) error {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return nil
+		}
+		return overlay(v.Elem(), name, unset)
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			f := v.Type().Field(i)
+			key, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+			if !f.IsExported() || key == "-" {
+				continue
+			}
+			if len(key) == 0 {
+				key = f.Name
+			}
+			if err := overlay(v.Field(i), name+"_"+strings.ToUpper(key), unset); err != nil {
+				return err
+			}
+		}
+	case reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		// the dynamic value is not settable in place, so it is overlaid on a copy which then replaces it
+		e := reflect.New(v.Elem().Type()).Elem()
+		e.Set(v.Elem())
+		if err := overlay(e, name, unset); err != nil {
+			return err
+		}
+		v.Set(e)
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return nil
+		}
+		keys := v.MapKeys()
+		slices.SortFunc(keys, func(a, b reflect.Value) int {
+			return strings.Compare(a.String(), b.String())
+		})
+		for _, k := range keys {
+			e := reflect.New(v.Type().Elem()).Elem()
+			e.Set(v.MapIndex(k))
+			if err := overlay(e, name+"_"+strings.ToUpper(k.String()), unset); err != nil {
+				return err
+			}
+			v.SetMapIndex(k, e)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := overlay(v.Index(i), name+"_"+strconv.Itoa(i), unset); err != nil {
+				return err
+			}
+		}
+	case reflect.String:
+		s := placeholder.ReplaceAllStringFunc(v.String(), func(m string) string {
+			value, ok := os.LookupEnv(m[2 : len(m)-1])
+			if !ok {
+				*unset = append(*unset, m[2:len(m)-1])
+			}
+			return value
+		})
+		if value, ok := os.LookupEnv(name); ok {
+			s = value
+		}
+		v.SetString(s)
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Float32, reflect.Float64:
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return nil
+		}
+		return setScalar(v, name, value)
+	}
+	return nil
+}
+
+func setScalar(v reflect.Value, name, value string) error {
+	if v.Type() == reflect.TypeFor[time.Duration]() {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("%s:%w", name, err)
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%s:%w", name, err)
+		}
+		v.SetBool(b)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("%s:%w", name, err)
+		}
+		v.SetFloat(f)
+	default:
+		i, err := strconv.ParseInt(value, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("%s:%w", name, err)
+		}
+		v.SetInt(i)
+	}
+	return nil
+}
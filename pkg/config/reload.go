@@ -0,0 +1,117 @@
+package config
+
+import (
+	"context"
+	"path/filepath"
+	"sync/atomic"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Reloadable holds the configuration loaded from a file and replaces it when the file changes; see Watch
+type Reloadable[B any] struct {
+	path    string
+	current atomic.Pointer[Config[B]]
+	watcher *fsnotify.Watcher
+	// resolved is where path led when it was last loaded, see Watch
+	resolved string
+	reloadSettings
+}
+
+type reloadSettings struct {
+	onError func(error)
+}
+
+// ReloadOpt configures a Reloadable; see NewReloadable
+type ReloadOpt func(*reloadSettings)
+
+// WithReloadErrors calls fn with the error of every reload that failed to decode or validate, e.g. a half written file or a bad edit
+func WithReloadErrors(fn func(error)) ReloadOpt {
+	return func(s *reloadSettings) {
+		s.onError = fn
+	}
+}
+
+// NewReloadable loads the configuration at path like NewConfigStrict and starts watching it, so changes made once it returns are
+// picked up by Watch; call Close if Watch is never called
+func NewReloadable[B any](path string, opts ...ReloadOpt) (*Reloadable[B], error) {
+	config, err := NewConfigStrict[B](path)
+	if err != nil {
+		return nil, err
+	}
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	// the directory is watched since editors and deployments often replace the file instead of writing it
+	if err := w.Add(filepath.Dir(path)); err != nil {
+		w.Close()
+		return nil, err
+	}
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		w.Close()
+		return nil, err
+	}
+	r := &Reloadable[B]{path: path, watcher: w, resolved: resolved}
+	for _, opt := range opts {
+		opt(&r.reloadSettings)
+	}
+	r.current.Store(config)
+	return r, nil
+}
+
+// Provide returns the configuration currently in effect; it is safe to call while Watch replaces it
+func (r *Reloadable[B]) Provide() *Config[B] {
+	return r.current.Load()
+}
+
+// Watch reloads the configuration whenever its file is written or replaced until ctx is done and then releases the watch; it can be
+// called once. A file that is a symlink is also reloaded when its target changes, e.g. when Kubernetes swaps the ..data link of a
+// mounted ConfigMap. A reload that fails to decode or validate is reported through WithReloadErrors and keeps the previous
+// configuration in effect. onChange is called with every configuration that replaced the previous one
+func (r *Reloadable[B]) Watch(ctx context.Context, onChange func(*Config[B])) error {
+	defer r.watcher.Close()
+	target := filepath.Clean(r.path)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err, ok := <-r.watcher.Errors:
+			if !ok {
+				return nil
+			}
+			return err
+		case ev, ok := <-r.watcher.Events:
+			if !ok {
+				return nil
+			}
+			written := filepath.Clean(ev.Name) == target && (ev.Has(fsnotify.Write) || ev.Has(fsnotify.Create))
+			// a symlink swap only reports an event for a link on the way to the file, so compare where the path leads
+			current, err := filepath.EvalSymlinks(r.path)
+			if err != nil {
+				continue
+			}
+			if !written && current == r.resolved {
+				continue
+			}
+			r.resolved = current
+			config, err := NewConfigStrict[B](r.path)
+			if err != nil {
+				if r.onError != nil {
+					r.onError(err)
+				}
+				continue
+			}
+			r.current.Store(config)
+			if onChange != nil {
+				onChange(config)
+			}
+		}
+	}
+}
+
+// Close releases the watch started by NewReloadable; it is only needed if Watch is never called
+func (r *Reloadable[B]) Close() error {
+	return r.watcher.Close()
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Validator is implemented by configurations that can check themselves; see NewConfigStrict
+type Validator interface {
+	Validate() error
+}
+
+// FieldErr describes a problem with the configuration value at the yaml path Path, e.g. sources[0].location
+type FieldErr struct {
+	Path    string
+	Problem string
+}
+
+// ValidationErr collects every problem found by a Validate implementation so they can be fixed at once
+type ValidationErr struct {
+	Fields []FieldErr
+}
+
+func (e *ValidationErr) Error() string {
+	problems := make([]string, len(e.Fields))
+	for idx, f := range e.Fields {
+		problems[idx] = f.Path + ":" + f.Problem
+	}
+	return fmt.Sprintf("invalid configuration;%s", strings.Join(problems, ";"))
+}
+
+// Add records a problem at path; it is meant to be used while building the error in Validate
+func (e *ValidationErr) Add(path, problem string) {
+	e.Fields = append(e.Fields, FieldErr{Path: path, Problem: problem})
+}
+
+// OrNil returns e if it holds any problem and nil otherwise, so Validate can end with return errs.OrNil()
+func (e *ValidationErr) OrNil() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+// NewConfigStrict works like NewConfig and then validates the configuration if *B implements Validator
+func NewConfigStrict[B any](path string) (*Config[B], error) {
+	config, err := NewConfig[B](path)
+	if err != nil {
+		return nil, err
+	}
+	if err := validate(&config.Base); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// NewConfigStrictFromBytes works like NewConfigFromBytes and then validates the configuration if *B implements Validator
+func NewConfigStrictFromBytes[B any](b []byte) (*Config[B], error) {
+	config, err := NewConfigFromBytes[B](b)
+	if err != nil {
+		return nil, err
+	}
+	if err := validate(&config.Base); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+func validate(base any) error {
+	if v, ok := base.(Validator); ok {
+		return v.Validate()
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package datamanagement
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ivanehh/boiler/internal/helpers/errors"
+)
+
+// WriteCSV writes the header followed by every row to w, separating values with sep; values containing sep, a line break
+// or a double quote are quoted as in RFC 4180. Rows whose length differs from the header fail with a *RowWidthErr before anything is written
+func (d *Dataframe) WriteCSV(w io.Writer, sep string) error {
+	mismatch := &errors.RowWidthErr{Width: len(d.Columns)}
+	for idx, r := range d.Rows {
+		if len(r) != len(d.Columns) {
+			mismatch.Rows = append(mismatch.Rows, idx)
+			mismatch.Widths = append(mismatch.Widths, len(r))
+		}
+	}
+	if len(mismatch.Rows) > 0 {
+		return mismatch
+	}
+	bw := bufio.NewWriter(w)
+	writeRecord := func(values []string) error {
+		for idx, v := range values {
+			if idx > 0 {
+				if _, err := bw.WriteString(sep); err != nil {
+					return err
+				}
+			}
+			if strings.Contains(v, sep) || strings.ContainsAny(v, "\"\r\n") {
+				v = `"` + strings.ReplaceAll(v, `"`, `""`) + `"`
+			}
+			if _, err := bw.WriteString(v); err != nil {
+				return err
+			}
+		}
+		_, err := bw.WriteString("\r\n")
+		return err
+	}
+	if err := writeRecord(d.Header()); err != nil {
+		return err
+	}
+	for _, r := range d.Rows {
+		values := make([]string, len(d.Columns))
+		for cid, c := range d.Columns {
+			values[cid] = r[c.idx]
+		}
+		if err := writeRecord(values); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// WriteCSVFile writes the dataframe to the file at path, creating or truncating it; see WriteCSV
+func (d *Dataframe) WriteCSVFile(path, sep string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := d.WriteCSV(f, sep); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
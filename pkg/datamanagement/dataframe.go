@@ -2,16 +2,27 @@ package datamanagement
 
 import (
 	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	stdErrors "errors"
 	"fmt"
+	"io"
+	"log/slog"
+	"path/filepath"
 	"reflect"
 	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/ivanehh/boiler/internal/helpers/errors"
+	"github.com/ivanehh/boiler/pkg/logging"
 	"github.com/pbnjay/grate"
 	_ "github.com/pbnjay/grate/simple"
 	_ "github.com/pbnjay/grate/xls"
+	_ "github.com/pbnjay/grate/xlsx"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/htmlindex"
 )
 
 type (
@@ -26,13 +37,38 @@ type (
 	Record []string
 )
 
+var (
+	ErrColumnCollision = stdErrors.New("column name already in use")
+	ErrNoRows          = stdErrors.New("the dataframe has no rows to take the header from")
+	ErrNoSheets        = stdErrors.New("the file contains no sheets")
+	ErrRowOutOfRange   = stdErrors.New("row index out of range")
+	ErrNoFrames        = stdErrors.New("no dataframes were given")
+	// ErrPartitionCollision is returned by WritePartitioned when distinct values map to the same file name, e.g. "a/b" and "a_b"
+	ErrPartitionCollision = stdErrors.New("partition values share a file name")
+)
+
 type Dataframe struct {
-	Columns []Column
-	Rows    []Record
-	cleaned bool
+	Columns     []Column
+	Rows        []Record
+	cleaned     bool
+	strictWidth bool
+	decoder     *encoding.Decoder
+	// collectErrors keeps applying opts after one fails; see WithErrorCollection
+	collectErrors bool
+	loadMetrics   func(LoadStat)
+	maxRows       int
+	truncated     bool
+	caseSensitive bool
+	trimCutset    string
+	dropEmptyRows bool
+	logger        *logging.Logger
 }
 
+// utf8BOM is stripped from the start of loaded text and files
+const utf8BOM = "\uFEFF"
+
 // DfRowsAsStructList the dataframe as a []sType representation; sType must have 'df' tags
+// string, float64, int, int64, bool and time.Time fields are filled; time.Time fields take their layout from the tag (df:"date,2006-01-02")
 func DfRowsAsStructList[sType any](d *Dataframe) ([]sType, error) {
 	var err error
 	result := make([]sType, len(d.Rows))
@@ -45,27 +81,57 @@ func DfRowsAsStructList[sType any](d *Dataframe) ([]sType, error) {
 		sType := sValue.Type()
 		for i := 0; i < sValue.NumField(); i++ {
 			field := sValue.Field(i)
-			fieldTag := strings.ToLower(sType.Field(i).Tag.Get("df"))
+			// the tag may carry a time layout after the column name: df:"date,2006-01-02"
+			tagName, layout, _ := strings.Cut(sType.Field(i).Tag.Get("df"), ",")
+			fieldTag := d.normalizeName(tagName)
 			if len(fieldTag) == 0 || fieldTag == "-" {
 				continue
 			}
-			if !slices.Contains(d.Header(), fieldTag) {
-				continue
-			}
 			for cid := range d.Columns {
-				if d.Columns[cid].name == fieldTag {
+				if d.sameName(d.Columns[cid].name, fieldTag) {
+					v := d.Rows[idx][d.Columns[cid].idx]
 					switch field.Kind() {
 					case reflect.String:
-						field.SetString(d.Rows[idx][cid])
+						field.SetString(v)
 						rPointers[idx] = s
 					case reflect.Float64:
 						var fv float64
-						fv, err = strconv.ParseFloat(d.Rows[idx][cid], 64)
+						fv, err = strconv.ParseFloat(v, 64)
 						if err != nil {
 							return nil, err
 						}
 						field.SetFloat(fv)
 						rPointers[idx] = s
+					case reflect.Int, reflect.Int64:
+						var iv int64
+						iv, err = strconv.ParseInt(v, 10, 64)
+						if err != nil {
+							return nil, fmt.Errorf("column %s row %d:%w", fieldTag, idx, err)
+						}
+						field.SetInt(iv)
+						rPointers[idx] = s
+					case reflect.Bool:
+						var bv bool
+						bv, err = strconv.ParseBool(v)
+						if err != nil {
+							return nil, fmt.Errorf("column %s row %d:%w", fieldTag, idx, err)
+						}
+						field.SetBool(bv)
+						rPointers[idx] = s
+					case reflect.Struct:
+						if field.Type() != reflect.TypeOf(time.Time{}) {
+							break
+						}
+						layouts := dateLayouts
+						if len(layout) != 0 {
+							layouts = []string{layout}
+						}
+						tv, ok := parseDate(v, layouts, false)
+						if !ok {
+							return nil, fmt.Errorf("column %s row %d:%w", fieldTag, idx, &time.ParseError{Layout: layouts[0], Value: v})
+						}
+						field.Set(reflect.ValueOf(tv))
+						rPointers[idx] = s
 					}
 					break
 				}
@@ -78,6 +144,57 @@ func DfRowsAsStructList[sType any](d *Dataframe) ([]sType, error) {
 	return result, nil
 }
 
+// NewDataframeFromStructs builds a dataframe from items using the 'df' tags of sType as columns in field order; the inverse of DfRowsAsStructList.
+// Untagged, unexported and df:"-" fields are skipped, floats are formatted without trailing zeros and time.Time uses the tag layout or RFC3339
+func NewDataframeFromStructs[sType any](items []sType) (*Dataframe, error) {
+	st := reflect.TypeOf((*sType)(nil)).Elem()
+	if st.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%s is not a struct type", st)
+	}
+	d := &Dataframe{cleaned: true, Rows: make([]Record, 0, len(items))}
+	fields := make([]int, 0)
+	layouts := make([]string, 0)
+	for i := 0; i < st.NumField(); i++ {
+		tagName, layout, _ := strings.Cut(st.Field(i).Tag.Get("df"), ",")
+		// reflect cannot read unexported fields, a tag on one is ignored
+		if len(tagName) == 0 || tagName == "-" || !st.Field(i).IsExported() {
+			continue
+		}
+		if len(layout) == 0 {
+			layout = time.RFC3339
+		}
+		d.Columns = append(d.Columns, Column{name: d.normalizeName(tagName), idx: len(d.Columns), content: make([]string, 0)})
+		fields = append(fields, i)
+		layouts = append(layouts, layout)
+	}
+	for _, item := range items {
+		sValue := reflect.ValueOf(item)
+		r := make(Record, len(fields))
+		for cid, fi := range fields {
+			field := sValue.Field(fi)
+			switch field.Kind() {
+			case reflect.String:
+				r[cid] = field.String()
+			case reflect.Float32, reflect.Float64:
+				r[cid] = strconv.FormatFloat(field.Float(), 'f', -1, field.Type().Bits())
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+				r[cid] = strconv.FormatInt(field.Int(), 10)
+			case reflect.Bool:
+				r[cid] = strconv.FormatBool(field.Bool())
+			default:
+				if t, ok := field.Interface().(time.Time); ok {
+					r[cid] = t.Format(layouts[cid])
+					continue
+				}
+				r[cid] = fmt.Sprint(field.Interface())
+			}
+		}
+		d.Rows = append(d.Rows, r)
+	}
+	d.fillContent()
+	return d, nil
+}
+
 func (d *Dataframe) Header() []string {
 	header := make([]string, len(d.Columns))
 	for i := range d.Columns {
@@ -86,94 +203,490 @@ func (d *Dataframe) Header() []string {
 	return header
 }
 
+// WithEncoding transcodes the loaded records from enc (e.g. "windows-1252", "iso-8859-1") to UTF-8; it must precede the record loading opts.
+// It applies to text sources only (text, NDJSON and .csv, .tsv or .txt files); spreadsheet cells are already decoded by their format
+func WithEncoding(enc string) DfOpt {
+	return func(d *Dataframe) error {
+		e, err := htmlindex.Get(enc)
+		if err != nil {
+			return fmt.Errorf("unsupported encoding %q:%w", enc, err)
+		}
+		d.decoder = e.NewDecoder()
+		return nil
+	}
+}
+
+// decode strips a leading BOM from s and transcodes it to UTF-8 if an encoding was set
+func (d *Dataframe) decode(s string) (string, error) {
+	s = strings.TrimPrefix(s, utf8BOM)
+	if d.decoder != nil {
+		var err error
+		if s, err = d.decoder.String(s); err != nil {
+			return "", fmt.Errorf("failed to transcode record:%w", err)
+		}
+	}
+	return s, nil
+}
+
+// WithRecordsFromText splits b into records on newLine and into values on sep; a "\n" newLine also accepts "\r\n" line endings.
+// With a line break newLine and a single character sep the text is read as RFC 4180 CSV, so quoted values may contain sep,
+// line breaks and "" escaped quotes; other combinations are split literally
 func WithRecordsFromText(b []byte, newLine string, sep string) DfOpt {
 	return func(d *Dataframe) error {
+		b, err := d.decodeText(b)
+		if err != nil {
+			return err
+		}
+		if comma := []rune(sep); len(comma) == 1 && (newLine == "\n" || newLine == "\r\n") {
+			return d.readCSV(b, comma[0])
+		}
 		csvRecords := bytes.Split(b, []byte(newLine))
+		// a trailing newline must not produce a phantom empty record
+		for len(csvRecords) > 0 && len(csvRecords[len(csvRecords)-1]) == 0 {
+			csvRecords = csvRecords[:len(csvRecords)-1]
+		}
 		for _, r := range csvRecords {
+			if newLine == "\n" {
+				r = bytes.TrimSuffix(r, []byte("\r"))
+			}
 			dfRecord := make(Record, 0)
 			csvValues := bytes.Split(r, []byte(sep))
 			for _, v := range csvValues {
 				dfRecord = append(dfRecord, string(v))
 			}
-			d.Rows = append(d.Rows, dfRecord)
+			if !d.appendRecord(dfRecord) {
+				break
+			}
+		}
+		return nil
+	}
+}
+
+// decodeText strips a leading BOM from b and transcodes it to UTF-8 if an encoding was set
+func (d *Dataframe) decodeText(b []byte) ([]byte, error) {
+	b = bytes.TrimPrefix(b, []byte(utf8BOM))
+	if d.decoder != nil {
+		var err error
+		if b, err = d.decoder.Bytes(b); err != nil {
+			return nil, fmt.Errorf("failed to transcode records:%w", err)
+		}
+	}
+	return b, nil
+}
+
+// readCSV appends the records of b parsed with encoding/csv; blank lines are skipped and rows may differ in length, clean() deals with those
+func (d *Dataframe) readCSV(b []byte, comma rune) error {
+	r := csv.NewReader(bytes.NewReader(b))
+	r.Comma = comma
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	for {
+		values, err := r.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to parse records:%w", err)
+		}
+		if !d.appendRecord(Record(values)) {
+			return nil
+		}
+	}
+}
+
+// WithRecordsFromNDJSON parses each non-empty line of b as a JSON object; the union of the keys (in order of first appearance) becomes the first row
+// so that it can be picked up by WithInterpretedColumns; keys missing from a line are filled with empty strings
+func WithRecordsFromNDJSON(b []byte) DfOpt {
+	return func(d *Dataframe) error {
+		b, err := d.decodeText(b)
+		if err != nil {
+			return err
+		}
+		var keys []string
+		var objects []map[string]any
+		for ln, line := range bytes.Split(b, []byte("\n")) {
+			line = bytes.TrimSpace(line)
+			if len(line) == 0 {
+				continue
+			}
+			dec := json.NewDecoder(bytes.NewReader(line))
+			dec.UseNumber()
+			obj := make(map[string]any)
+			if err := dec.Decode(&obj); err != nil {
+				return fmt.Errorf("failed to parse NDJSON line %d:%w", ln+1, err)
+			}
+			// the decoder does not report the keys in order, so walk the raw object to keep them stable
+			ordered, err := objectKeys(line)
+			if err != nil {
+				return fmt.Errorf("failed to parse NDJSON line %d:%w", ln+1, err)
+			}
+			for _, k := range ordered {
+				if !slices.Contains(keys, k) {
+					keys = append(keys, k)
+				}
+			}
+			objects = append(objects, obj)
+		}
+		if len(keys) == 0 {
+			return nil
+		}
+		if !d.appendRecord(Record(slices.Clone(keys))) {
+			return nil
+		}
+		for _, obj := range objects {
+			r := make(Record, len(keys))
+			for idx, k := range keys {
+				r[idx] = ndjsonValue(obj[k])
+			}
+			if !d.appendRecord(r) {
+				break
+			}
 		}
 		return nil
 	}
 }
 
+func objectKeys(line []byte) ([]string, error) {
+	dec := json.NewDecoder(bytes.NewReader(line))
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0)
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, t.(string))
+		var skip json.RawMessage
+		if err := dec.Decode(&skip); err != nil {
+			return nil, err
+		}
+	}
+	return keys, nil
+}
+
+func ndjsonValue(v any) string {
+	switch tv := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return tv
+	case json.Number:
+		return tv.String()
+	case bool:
+		return strconv.FormatBool(tv)
+	default:
+		b, err := json.Marshal(tv)
+		if err != nil {
+			return ""
+		}
+		return string(b)
+	}
+}
+
+// ListSheets returns the names of the sheets in the workbook at filePath; flat files such as CSV report a single sheet
+func ListSheets(filePath string) ([]string, error) {
+	source, err := grate.Open(filePath)
+	if err != nil {
+		if stdErrors.Is(err, grate.ErrUnknownFormat) {
+			return nil, fmt.Errorf("%s is not a supported spreadsheet format:%w", filePath, err)
+		}
+		return nil, err
+	}
+	defer source.Close()
+	return source.List()
+}
+
+// LoadStat describes the loading of a single file by WithRecordsFromFiles
+type LoadStat struct {
+	Path string
+	Rows int
+	// Rejects counts only the blank rows dropped while reading the file; the rows that WithInterpretedColumns later removes for not
+	// matching the header width are not attributed to a file and are reported through WithLogger or WithStrictWidth instead
+	Rejects  int
+	Duration time.Duration
+	Err      error
+}
+
+// WithLoadMetrics calls fn once for every file loaded by WithRecordsFromFiles, including the one that failed; it must precede the loading opts
+func WithLoadMetrics(fn func(LoadStat)) DfOpt {
+	return func(d *Dataframe) error {
+		d.loadMetrics = fn
+		return nil
+	}
+}
+
+// Preview loads the header and at most n data rows of the first sheet of filePath; reading stops as soon as the rows are collected.
+// A negative n is an error
+func Preview(filePath string, n int) (*Dataframe, error) {
+	if n < 0 {
+		return nil, fmt.Errorf("cannot preview %d rows of %s", n, filePath)
+	}
+	if n > 0 {
+		return NewDataframe(WithMaxRows(n), WithRecordsFromFiles([]string{filePath}), WithInterpretedColumns())
+	}
+	// WithMaxRows(0) would not limit the rows, so a single one is loaded and then dropped
+	d, err := NewDataframe(WithMaxRows(1), WithRecordsFromFiles([]string{filePath}), WithInterpretedColumns())
+	if err != nil {
+		return nil, err
+	}
+	d.truncated = d.truncated || len(d.Rows) > 0
+	d.Rows = d.Rows[:0]
+	d.fillContent()
+	return d, nil
+}
+
+// WithRecordsFromFiles loads the first sheet of every file in filePaths; see WithRecordsFromSheet for other sheets
 func WithRecordsFromFiles(filePaths []string) DfOpt {
+	return withRecordsFromSheet(filePaths, firstSheet)
+}
+
+// WithRecordsFromSheet works like WithRecordsFromFiles but reads the sheet named sheet from every file; a file without it fails with a *SheetNotFoundErr
+func WithRecordsFromSheet(filePaths []string, sheet string) DfOpt {
+	return withRecordsFromSheet(filePaths, func(fp string, sheets []string) (string, error) {
+		if !slices.Contains(sheets, sheet) {
+			return "", &errors.SheetNotFoundErr{File: fp, Sheet: sheet, Available: sheets}
+		}
+		return sheet, nil
+	})
+}
+
+// WithRecordsFromSheetIndex works like WithRecordsFromFiles but reads the sheet at position idx (0 being the first) from every file
+func WithRecordsFromSheetIndex(filePaths []string, idx int) DfOpt {
+	return withRecordsFromSheet(filePaths, func(fp string, sheets []string) (string, error) {
+		if idx < 0 || idx >= len(sheets) {
+			return "", &errors.SheetNotFoundErr{File: fp, Sheet: strconv.Itoa(idx), Available: sheets}
+		}
+		return sheets[idx], nil
+	})
+}
+
+// sheetSelector picks the sheet to load from the sheets of the file at fp
+type sheetSelector func(fp string, sheets []string) (string, error)
+
+func firstSheet(fp string, sheets []string) (string, error) {
+	if len(sheets) == 0 {
+		return "", fmt.Errorf("%w:%s", ErrNoSheets, fp)
+	}
+	return sheets[0], nil
+}
+
+func withRecordsFromSheet(filePaths []string, sheet sheetSelector) DfOpt {
 	return func(d *Dataframe) error {
 		var head []string
 		for idx, fp := range filePaths {
-			source, err := grate.Open(fp)
-			if err != nil {
-				return err
-			}
-			sheets, err := source.List()
-			if err != nil {
-				return err
+			stat := LoadStat{Path: fp}
+			started := time.Now()
+			err := d.loadFile(idx, fp, sheet, &head, &stat)
+			if d.loadMetrics != nil {
+				stat.Duration = time.Since(started)
+				stat.Err = err
+				d.loadMetrics(stat)
 			}
-			data, err := source.Get(sheets[0])
 			if err != nil {
 				return err
 			}
-			/*
-				this part is a bit awkward
-				if we are not at the first file then we want to skip the header
-			*/
-			if idx != 0 {
-				for data.Next() {
-					// advance rows as long as they are empty
-					if len(data.Strings()[0]) < 1 || len(data.Strings()[0]) == 0 {
-						continue
-					}
-					// do not generate dataframe for file sets that do not have identical headers
-					if head != nil {
-						var cr Record
-						r := data.Strings()
-						if strings.Contains(r[0], ",") {
-							if cr = cleanRecord(strings.Split(r[0], ",")); len(cr) > 0 {
-								if slices.Compare(head, cr) != 0 {
-									return &errors.HeaderMismatchErr{
-										Original: head,
-										Mismatch: cr,
-									}
-								}
-							}
-						} else {
-							if cr = cleanRecord(r); len(cr) > 0 {
-								if slices.Compare(head, cr) != 0 {
-									return &errors.HeaderMismatchErr{
-										Original: head,
-										Mismatch: cr,
-									}
-								}
-							}
-						}
-					}
-					break
-				}
+		}
+		return nil
+	}
+}
+
+// loadFile appends the records of the sheet of fp picked by sheet; files after the first (idx != 0) must repeat the header found in the first one
+func (d *Dataframe) loadFile(idx int, fp string, sheet sheetSelector, head *[]string, stat *LoadStat) error {
+	source, err := grate.Open(fp)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+	sheets, err := source.List()
+	if err != nil {
+		return err
+	}
+	name, err := sheet(fp, sheets)
+	if err != nil {
+		return err
+	}
+	data, err := source.Get(name)
+	if err != nil {
+		return err
+	}
+	decode := d.decodeAll
+	if !isTextFile(fp) {
+		decode = keepCells
+	}
+	/*
+		this part is a bit awkward
+		if we are not at the first file then we want to skip the header
+	*/
+	if idx != 0 {
+		for data.Next() {
+			// advance rows as long as they are empty
+			if len(data.Strings()[0]) < 1 || len(data.Strings()[0]) == 0 {
+				continue
 			}
-			for data.Next() {
-				r := data.Strings()
+			// do not generate dataframe for file sets that do not have identical headers
+			if *head != nil {
 				var cr Record
+				r, err := decode(data.Strings())
+				if err != nil {
+					return err
+				}
 				if strings.Contains(r[0], ",") {
-					if cr = cleanRecord(strings.Split(r[0], ",")); len(cr) > 0 {
-						d.Rows = append(d.Rows, cr)
+					if cr = d.cleanRecord(strings.Split(r[0], ",")); len(cr) > 0 {
+						if slices.Compare(*head, cr) != 0 {
+							return &errors.HeaderMismatchErr{
+								Original: *head,
+								Mismatch: cr,
+							}
+						}
 					}
 				} else {
-					if cr = cleanRecord(r); len(cr) > 0 {
-						d.Rows = append(d.Rows, cr)
+					if cr = d.cleanRecord(r); len(cr) > 0 {
+						if slices.Compare(*head, cr) != 0 {
+							return &errors.HeaderMismatchErr{
+								Original: *head,
+								Mismatch: cr,
+							}
+						}
 					}
 				}
-				// set the default header for this dataframe
-				if slices.ContainsFunc(cr, func(e string) bool {
-					return strings.EqualFold(e, "date")
-				}) && head == nil {
-					head = cleanRecord(cr)
-				}
 			}
+			break
 		}
+	}
+	var blank []Record
+	for data.Next() {
+		r, err := decode(data.Strings())
+		if err != nil {
+			return err
+		}
+		var cr Record
+		if strings.Contains(r[0], ",") {
+			cr = d.cleanRecord(strings.Split(r[0], ","))
+		} else {
+			cr = d.cleanRecord(r)
+		}
+		// blank rows before the header are never data, they would otherwise end up as the header
+		if len(cr) == 0 || emptyRecord(cr) && (d.dropEmptyRows || len(d.Rows) == 0) {
+			stat.Rejects++
+			continue
+		}
+		// blank rows are held back until a non-blank one follows; the ones ending a sheet are padding and are rejected
+		if emptyRecord(cr) {
+			blank = append(blank, cr)
+			continue
+		}
+		for len(blank) > 0 && d.appendRecord(blank[0]) {
+			blank = blank[1:]
+			stat.Rows++
+		}
+		// the row limit was reached; the blank rows left are counted below
+		if len(blank) > 0 {
+			break
+		}
+		if !d.appendRecord(cr) {
+			break
+		}
+		stat.Rows++
+		// set the default header for this dataframe
+		if slices.ContainsFunc(cr, func(e string) bool {
+			return strings.EqualFold(e, "date")
+		}) && *head == nil {
+			*head = d.cleanRecord(cr)
+		}
+	}
+	stat.Rejects += len(blank)
+	return nil
+}
+
+// WithLogger makes the dataframe report the records it drops or renames through l instead of the default slog logger
+func WithLogger(l *logging.Logger) DfOpt {
+	return func(d *Dataframe) error {
+		d.logger = l
+		return nil
+	}
+}
+
+func (d *Dataframe) warn(msg string, attrs ...any) {
+	if d.logger != nil {
+		d.logger.Warn(msg, attrs...)
+		return
+	}
+	slog.Warn(msg, attrs...)
+}
+
+// WithMaxRows stops loading once n data rows are in the dataframe, across all loading opts and files; the header row loaded for
+// WithInterpretedColumns is not counted and n <= 0 means no limit. It must precede the loading opts; see Truncated
+func WithMaxRows(n int) DfOpt {
+	return func(d *Dataframe) error {
+		d.maxRows = n
+		return nil
+	}
+}
+
+// Truncated reports whether loading stopped early because of WithMaxRows
+func (d *Dataframe) Truncated() bool {
+	return d.truncated
+}
+
+// appendRecord adds r unless the WithMaxRows limit has been reached; it reports whether r was added
+func (d *Dataframe) appendRecord(r Record) bool {
+	limit := d.maxRows
+	if len(d.Columns) == 0 {
+		// the columns are not interpreted yet, so the first record is the header
+		limit++
+	}
+	if d.maxRows > 0 && len(d.Rows) >= limit {
+		if !d.truncated {
+			d.warn("row limit reached, the remaining records are not loaded", "limit", d.maxRows)
+		}
+		d.truncated = true
+		return false
+	}
+	d.Rows = append(d.Rows, r)
+	return true
+}
+
+// isTextFile reports whether the file at fp holds plain text, whose encoding is unknown, rather than a spreadsheet
+func isTextFile(fp string) bool {
+	switch strings.ToLower(filepath.Ext(fp)) {
+	case ".csv", ".tsv", ".txt":
+		return true
+	}
+	return false
+}
+
+// keepCells stands in for decodeAll on spreadsheets, whose cells are decoded by grate
+func keepCells(r []string) ([]string, error) {
+	return r, nil
+}
+
+func (d *Dataframe) decodeAll(r []string) ([]string, error) {
+	decoded := make([]string, len(r))
+	for idx := range r {
+		var err error
+		if decoded[idx], err = d.decode(r[idx]); err != nil {
+			return nil, err
+		}
+	}
+	return decoded, nil
+}
+
+// WithErrorCollection makes NewDataframe apply every opt even if some fail and return the partial dataframe with the joined errors;
+// it only affects the opts that follow it, so pass it first
+func WithErrorCollection() DfOpt {
+	return func(d *Dataframe) error {
+		d.collectErrors = true
+		return nil
+	}
+}
+
+// WithStrictWidth fails the dataframe construction with a *RowWidthErr listing every row whose length differs from the header
+// instead of silently dropping those rows
+func WithStrictWidth() DfOpt {
+	return func(d *Dataframe) error {
+		d.strictWidth = true
 		return nil
 	}
 }
@@ -190,19 +703,52 @@ func WithCleanerFunc(cleaner func(*Dataframe) ([]Record, error)) DfOpt {
 	}
 }
 
-func cleanRecord(r []string) Record {
-	newR := make(Record, 0)
+// WithTrimCutset makes loading trim the characters in cutset from both ends of every cell instead of white space;
+// it must precede the loading opts and an empty cutset keeps the white space default
+func WithTrimCutset(cutset string) DfOpt {
+	return func(d *Dataframe) error {
+		d.trimCutset = cutset
+		return nil
+	}
+}
+
+// cleanRecord trims every cell of r; empty cells are kept in place so the remaining ones stay under their columns
+func (d *Dataframe) cleanRecord(r []string) Record {
+	newR := make(Record, len(r))
 	for idx := range r {
-		if len(r[idx]) > 0 {
-			newR = append(newR, strings.Trim(r[idx], " +-"))
-		}
+		newR[idx] = d.trim(r[idx])
 	}
 	return newR
 }
 
+// WithDropEmptyRows makes WithRecordsFromFiles skip rows whose cells are all empty, counting them as rejects; without it only the empty rows
+// ending a sheet are skipped. It must precede the loading opts
+func WithDropEmptyRows() DfOpt {
+	return func(d *Dataframe) error {
+		d.dropEmptyRows = true
+		return nil
+	}
+}
+
+func emptyRecord(r Record) bool {
+	return !slices.ContainsFunc(r, func(v string) bool {
+		return len(v) > 0
+	})
+}
+
+func (d *Dataframe) trim(v string) string {
+	if len(d.trimCutset) == 0 {
+		return strings.TrimSpace(v)
+	}
+	return strings.Trim(v, d.trimCutset)
+}
+
 // WithProvidedColumns does not remove the first row of the dataframe!
 func WithProvidedColumns(h []string) DfOpt {
 	return func(d *Dataframe) error {
+		if len(d.Rows) == 0 {
+			return ErrNoRows
+		}
 		if r := slices.Compare(h, d.Rows[0]); r != 0 {
 			// TODO: header mismatch error
 			return &errors.HeaderInterpretErr{Provided: h, Found: d.Rows[0]}
@@ -210,7 +756,7 @@ func WithProvidedColumns(h []string) DfOpt {
 
 		for idx, str := range h {
 			d.Columns = append(d.Columns, Column{
-				name:    strings.ToLower(strings.ReplaceAll(str, " ", "")),
+				name:    d.normalizeName(str),
 				idx:     idx,
 				content: make([]string, 0),
 			})
@@ -219,12 +765,41 @@ func WithProvidedColumns(h []string) DfOpt {
 	}
 }
 
+// WithCaseSensitiveColumns keeps the case of column names and matches them exactly; it must precede the column opts
+func WithCaseSensitiveColumns() DfOpt {
+	return func(d *Dataframe) error {
+		d.caseSensitive = true
+		return nil
+	}
+}
+
+// normalizeName removes the spaces from a column name and lowercases it unless the columns are case sensitive
+func (d *Dataframe) normalizeName(name string) string {
+	name = strings.ReplaceAll(name, " ", "")
+	if d.caseSensitive {
+		return name
+	}
+	return strings.ToLower(name)
+}
+
+// sameName reports whether two column names match, honoring WithCaseSensitiveColumns
+func (d *Dataframe) sameName(a, b string) bool {
+	if d.caseSensitive {
+		return a == b
+	}
+	return strings.EqualFold(a, b)
+}
+
 // WithInterpretedColumns uses the first row of the dataframe to interpret the column names; it then removes the row from the dataframe; this is the default behavior
 func WithInterpretedColumns() DfOpt {
 	return func(d *Dataframe) error {
-		for idx, str := range d.Rows[0] {
+		if len(d.Rows) == 0 {
+			return ErrNoRows
+		}
+		names := d.uniqueNames(d.Rows[0])
+		for idx, name := range names {
 			d.Columns = append(d.Columns, Column{
-				name:    strings.ToLower(strings.ReplaceAll(str, " ", "")),
+				name:    name,
 				idx:     idx,
 				content: make([]string, 0),
 			})
@@ -234,11 +809,234 @@ func WithInterpretedColumns() DfOpt {
 	}
 }
 
-// Drop a range of rows from the dataframe
-func (d *Dataframe) Drop(i ...int) {
+// uniqueNames normalizes the header names; repeated names get a numeric suffix (amount, amount_2) so every column is addressable
+func (d *Dataframe) uniqueNames(header []string) []string {
+	normalized := make([]string, len(header))
+	for idx, str := range header {
+		normalized[idx] = d.normalizeName(str)
+	}
+	names := make([]string, 0, len(header))
+	for _, name := range normalized {
+		if slices.Contains(names, name) {
+			base := name
+			// skip suffixes that are already taken by a header of the source
+			for n := 2; slices.Contains(names, name) || slices.Contains(normalized, name); n++ {
+				name = fmt.Sprintf("%s_%d", base, n)
+			}
+			d.warn("duplicate column renamed", "column", base, "renamed", name)
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// Clone returns a deep copy of the dataframe; the copy can be mutated without affecting d
+func (d *Dataframe) Clone() *Dataframe {
+	c := d.emptyCopy()
+	c.Rows = make([]Record, len(d.Rows))
+	for idx, col := range d.Columns {
+		c.Columns[idx].content = slices.Clone(col.content)
+	}
+	for idx, r := range d.Rows {
+		c.Rows[idx] = slices.Clone(r)
+	}
+	return c
+}
+
+// emptyCopy returns a frame with the columns and settings of d but no rows; the column contents are left empty for fillContent
+func (d *Dataframe) emptyCopy() *Dataframe {
+	c := &Dataframe{
+		Columns:       make([]Column, len(d.Columns)),
+		Rows:          make([]Record, 0),
+		cleaned:       d.cleaned,
+		strictWidth:   d.strictWidth,
+		decoder:       d.decoder,
+		collectErrors: d.collectErrors,
+		loadMetrics:   d.loadMetrics,
+		maxRows:       d.maxRows,
+		truncated:     d.truncated,
+		caseSensitive: d.caseSensitive,
+		trimCutset:    d.trimCutset,
+		dropEmptyRows: d.dropEmptyRows,
+		logger:        d.logger,
+	}
+	for idx, col := range d.Columns {
+		col.content = nil
+		c.Columns[idx] = col
+	}
+	return c
+}
+
+// Append adds copies of the rows of other to d; both frames must share a header, names and order included
+func (d *Dataframe) Append(other *Dataframe) error {
+	if slices.Compare(d.Header(), other.Header()) != 0 {
+		return &errors.HeaderMismatchErr{Original: d.Header(), Mismatch: other.Header()}
+	}
+	for _, r := range other.Rows {
+		nr := make(Record, len(d.Columns))
+		for cid, c := range d.Columns {
+			nr[c.idx] = r[other.Columns[cid].idx]
+		}
+		d.Rows = append(d.Rows, nr)
+	}
+	d.fillContent()
+	return nil
+}
+
+// Concat returns a new dataframe holding the rows of every frame in dfs in order; the frames must share a header, see Append
+func Concat(dfs ...*Dataframe) (*Dataframe, error) {
+	if len(dfs) == 0 {
+		return nil, ErrNoFrames
+	}
+	c := dfs[0].Clone()
+	for _, other := range dfs[1:] {
+		if err := c.Append(other); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// Diff compares d with other row by row using keyColumns as the row identity; added holds the rows only found in other,
+// removed the rows only found in d and changed the rows of other whose non-key values differ from d; both frames must share a header
+func (d *Dataframe) Diff(other *Dataframe, keyColumns []string) (added, removed, changed *Dataframe, err error) {
+	if slices.Compare(d.Header(), other.Header()) != 0 {
+		return nil, nil, nil, &errors.HeaderMismatchErr{Original: d.Header(), Mismatch: other.Header()}
+	}
+	keyIdx := make([]int, 0, len(keyColumns))
+	for _, kc := range keyColumns {
+		cid := slices.IndexFunc(d.Columns, func(c Column) bool {
+			return d.sameName(c.name, kc)
+		})
+		if cid < 0 {
+			return nil, nil, nil, &errors.ColumnsNotFoundErr{Available: d.Header(), Required: keyColumns}
+		}
+		keyIdx = append(keyIdx, d.Columns[cid].idx)
+	}
+	rowKey := func(r Record) string {
+		parts := make([]string, len(keyIdx))
+		for idx, ki := range keyIdx {
+			parts[idx] = r[ki]
+		}
+		return strings.Join(parts, "\x00")
+	}
+	before := make(map[string]Record, len(d.Rows))
+	for _, r := range d.Rows {
+		before[rowKey(r)] = r
+	}
+	added, removed, changed = d.emptyCopy(), d.emptyCopy(), d.emptyCopy()
+	seen := make(map[string]struct{}, len(other.Rows))
+	for _, r := range other.Rows {
+		k := rowKey(r)
+		seen[k] = struct{}{}
+		old, ok := before[k]
+		switch {
+		case !ok:
+			added.Rows = append(added.Rows, slices.Clone(r))
+		case slices.Compare(old, r) != 0:
+			changed.Rows = append(changed.Rows, slices.Clone(r))
+		}
+	}
+	for _, r := range d.Rows {
+		if _, ok := seen[rowKey(r)]; !ok {
+			removed.Rows = append(removed.Rows, slices.Clone(r))
+		}
+	}
+	added.fillContent()
+	removed.fillContent()
+	changed.fillContent()
+	return added, removed, changed, nil
+}
+
+// Rename renames columns by their current name, looked up like GetColumn, to the names in mapping; new names are normalized like
+// interpreted columns. errors if a source column is absent or a new name collides with another column; nothing is renamed on error
+func (d *Dataframe) Rename(mapping map[string]string) error {
+	current, names := d.Header(), d.Header()
+	for from, to := range mapping {
+		cid := slices.IndexFunc(current, func(n string) bool {
+			return d.sameName(n, from)
+		})
+		if cid < 0 {
+			return &errors.ColumnsNotFoundErr{Available: current, Required: []string{from}}
+		}
+		names[cid] = d.normalizeName(to)
+	}
+	for idx, n := range names {
+		if slices.Index(names, n) != idx {
+			return fmt.Errorf("%w:%s", ErrColumnCollision, n)
+		}
+	}
+	for cid := range d.Columns {
+		d.Columns[cid].name = names[cid]
+	}
+	return nil
+}
+
+// RenameColumn renames a single column, looking it up case-insensitively; see Rename
+func (d *Dataframe) RenameColumn(old, new string) error {
+	return d.Rename(map[string]string{old: new})
+}
+
+// DropColumns removes the named columns and their cells from every row; the remaining columns are re-indexed to the new row width
+// errors without removing anything if a name is absent
+func (d *Dataframe) DropColumns(names ...string) error {
+	drop := make([]int, 0, len(names))
+	for _, n := range names {
+		cid := slices.IndexFunc(d.Columns, func(c Column) bool {
+			return d.sameName(c.name, n)
+		})
+		if cid < 0 {
+			return &errors.ColumnsNotFoundErr{Available: d.Header(), Required: names}
+		}
+		drop = append(drop, d.Columns[cid].idx)
+	}
+	slices.Sort(drop)
+	drop = slices.Compact(drop)
+	for ridx, r := range d.Rows {
+		kept := make(Record, 0, len(r))
+		for idx, v := range r {
+			if !slices.Contains(drop, idx) {
+				kept = append(kept, v)
+			}
+		}
+		d.Rows[ridx] = kept
+	}
+	columns := make([]Column, 0, len(d.Columns))
+	for _, c := range d.Columns {
+		if slices.Contains(drop, c.idx) {
+			continue
+		}
+		shift := 0
+		for _, idx := range drop {
+			if idx < c.idx {
+				shift++
+			}
+		}
+		c.idx -= shift
+		columns = append(columns, c)
+	}
+	d.Columns = columns
+	return nil
+}
+
+// Drop removes every row whose index is listed in i; indices refer to the rows before the call and duplicates are ignored
+// errors without removing anything if an index is outside of d.Rows
+func (d *Dataframe) Drop(i ...int) error {
+	for _, idx := range i {
+		if idx < 0 || idx >= len(d.Rows) {
+			return fmt.Errorf("%w:%d", ErrRowOutOfRange, idx)
+		}
+	}
+	i = slices.Clone(i)
 	slices.Sort(i)
-	d.Rows = slices.Delete(d.Rows, i[0], i[len(i)-1])
-	d.clean()
+	i = slices.Compact(i)
+	// deleting from the highest index down keeps the lower ones pointing at the same rows
+	for _, idx := range slices.Backward(i) {
+		d.Rows = slices.Delete(d.Rows, idx, idx+1)
+	}
+	// rows were already validated on construction, deleting some cannot introduce width mismatches
+	_ = d.clean()
+	return nil
 }
 
 func (d *Dataframe) Get(row int, columns ...string) (*Dataframe, error) {
@@ -247,8 +1045,9 @@ func (d *Dataframe) Get(row int, columns ...string) (*Dataframe, error) {
 	r = d.Rows[row]
 	dnew := new(Dataframe)
 	if len(columns) == 0 {
-		dnew.Columns = d.Columns
+		dnew.Columns = slices.Clone(d.Columns)
 		dnew.Rows = []Record{d.Rows[row]}
+		dnew.fillContent()
 		return dnew, nil
 	}
 	for _, c := range d.Columns {
@@ -258,7 +1057,7 @@ func (d *Dataframe) Get(row int, columns ...string) (*Dataframe, error) {
 		// }
 		if slices.ContainsFunc(columns, func(e string) bool {
 			return func(dfc string) bool {
-				return strings.EqualFold(e, dfc)
+				return d.sameName(e, dfc)
 			}(c.name)
 		}) {
 			result = append(result, r[c.idx])
@@ -271,34 +1070,380 @@ func (d *Dataframe) Get(row int, columns ...string) (*Dataframe, error) {
 			Required:  columns,
 		}
 	}
+	for idx := range dnew.Columns {
+		dnew.Columns[idx].idx = idx
+	}
 	dnew.Rows = []Record{result}
+	dnew.fillContent()
 	return dnew, nil
 }
 
-func (d *Dataframe) clean() {
+// GetColumn returns the value of column for every row; the returned slice is a copy and can be modified freely.
+// The values are kept up to date by the Dataframe methods, changes made to d.Rows directly are not reflected
+func (d *Dataframe) GetColumn(column string) ([]string, error) {
+	cid := slices.IndexFunc(d.Columns, func(c Column) bool {
+		return d.sameName(c.name, column)
+	})
+	if cid < 0 {
+		return nil, &errors.ColumnsNotFoundErr{Available: d.Header(), Required: []string{column}}
+	}
+	return slices.Clone(d.Columns[cid].content), nil
+}
+
+// SortBy stably sorts the rows by the named columns in priority order, comparing values as strings; see SortByFunc for other orderings
+func (d *Dataframe) SortBy(columns ...string) error {
+	idxs := make([]int, 0, len(columns))
+	for _, column := range columns {
+		cid := slices.IndexFunc(d.Columns, func(c Column) bool {
+			return d.sameName(c.name, column)
+		})
+		if cid < 0 {
+			return &errors.ColumnsNotFoundErr{Available: d.Header(), Required: columns}
+		}
+		idxs = append(idxs, d.Columns[cid].idx)
+	}
+	slices.SortStableFunc(d.Rows, func(a, b Record) int {
+		for _, idx := range idxs {
+			if c := strings.Compare(a[idx], b[idx]); c != 0 {
+				return c
+			}
+		}
+		return 0
+	})
+	d.fillContent()
+	return nil
+}
+
+// SortByFunc stably sorts the rows by column using less, e.g. to order numeric columns numerically
+func (d *Dataframe) SortByFunc(column string, less func(a, b string) bool) error {
+	cid := slices.IndexFunc(d.Columns, func(c Column) bool {
+		return d.sameName(c.name, column)
+	})
+	if cid < 0 {
+		return &errors.ColumnsNotFoundErr{Available: d.Header(), Required: []string{column}}
+	}
+	idx := d.Columns[cid].idx
+	slices.SortStableFunc(d.Rows, func(a, b Record) int {
+		switch {
+		case less(a[idx], b[idx]):
+			return -1
+		case less(b[idx], a[idx]):
+			return 1
+		}
+		return 0
+	})
+	d.fillContent()
+	return nil
+}
+
+// GroupBy splits d into one dataframe per distinct value of column, keyed by that value; rows keep their order within a group
+func (d *Dataframe) GroupBy(column string) (map[string]*Dataframe, error) {
+	cid := slices.IndexFunc(d.Columns, func(c Column) bool {
+		return d.sameName(c.name, column)
+	})
+	if cid < 0 {
+		return nil, &errors.ColumnsNotFoundErr{Available: d.Header(), Required: []string{column}}
+	}
+	groups := make(map[string]*Dataframe)
+	for _, r := range d.Rows {
+		v := r[d.Columns[cid].idx]
+		g, ok := groups[v]
+		if !ok {
+			g = d.emptyCopy()
+			groups[v] = g
+		}
+		g.Rows = append(g.Rows, slices.Clone(r))
+	}
+	for _, g := range groups {
+		g.fillContent()
+	}
+	return groups, nil
+}
+
+// Sum adds up the values of column, skipping empty cells; the first value that is not a number is reported in a *CellParseErr
+func (d *Dataframe) Sum(column string) (float64, error) {
+	cid := slices.IndexFunc(d.Columns, func(c Column) bool {
+		return d.sameName(c.name, column)
+	})
+	if cid < 0 {
+		return 0, &errors.ColumnsNotFoundErr{Available: d.Header(), Required: []string{column}}
+	}
+	var sum float64
+	for idx, r := range d.Rows {
+		v := strings.TrimSpace(r[d.Columns[cid].idx])
+		if len(v) == 0 {
+			continue
+		}
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return 0, &errors.CellParseErr{Column: d.Columns[cid].name, Rows: []int{idx}, Values: []string{v}}
+		}
+		sum += f
+	}
+	return sum, nil
+}
+
+// ColumnStats summarizes the numeric cells of a column; Count, Min, Max and Mean cover the cells parsed as numbers only
+type ColumnStats struct {
+	Count      int
+	Min        float64
+	Max        float64
+	Mean       float64
+	NonNumeric int
+}
+
+// Describe computes ColumnStats for column; empty cells are skipped and cells that are not numbers are counted in NonNumeric
+func (d *Dataframe) Describe(column string) (ColumnStats, error) {
+	cid := slices.IndexFunc(d.Columns, func(c Column) bool {
+		return d.sameName(c.name, column)
+	})
+	if cid < 0 {
+		return ColumnStats{}, &errors.ColumnsNotFoundErr{Available: d.Header(), Required: []string{column}}
+	}
+	var stats ColumnStats
+	var sum float64
+	for _, r := range d.Rows {
+		v := strings.TrimSpace(r[d.Columns[cid].idx])
+		if len(v) == 0 {
+			continue
+		}
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			stats.NonNumeric++
+			continue
+		}
+		if stats.Count == 0 || f < stats.Min {
+			stats.Min = f
+		}
+		if stats.Count == 0 || f > stats.Max {
+			stats.Max = f
+		}
+		stats.Count++
+		sum += f
+	}
+	if stats.Count > 0 {
+		stats.Mean = sum / float64(stats.Count)
+	}
+	return stats, nil
+}
+
+// Count returns the number of rows in d
+func (d *Dataframe) Count() int {
+	return len(d.Rows)
+}
+
+// DropDuplicates removes the rows whose values in columns (every column when none are given) repeat an earlier row's,
+// keeping the first occurrence and the order of the kept rows; it returns the number of rows removed
+func (d *Dataframe) DropDuplicates(columns ...string) (int, error) {
+	idxs := make([]int, 0, len(columns))
+	for _, column := range columns {
+		cid := slices.IndexFunc(d.Columns, func(c Column) bool {
+			return d.sameName(c.name, column)
+		})
+		if cid < 0 {
+			return 0, &errors.ColumnsNotFoundErr{Available: d.Header(), Required: columns}
+		}
+		idxs = append(idxs, d.Columns[cid].idx)
+	}
+	if len(idxs) == 0 {
+		for _, c := range d.Columns {
+			idxs = append(idxs, c.idx)
+		}
+	}
+	seen := make(map[string]struct{}, len(d.Rows))
+	kept := make([]Record, 0, len(d.Rows))
+	for _, r := range d.Rows {
+		parts := make([]string, len(idxs))
+		for idx, ki := range idxs {
+			parts[idx] = r[ki]
+		}
+		k := strings.Join(parts, "\x00")
+		if _, ok := seen[k]; ok {
+			continue
+		}
+		seen[k] = struct{}{}
+		kept = append(kept, r)
+	}
+	removed := len(d.Rows) - len(kept)
+	d.Rows = kept
+	d.fillContent()
+	return removed, nil
+}
+
+// Distinct returns the unique values of column in order of first appearance; values differing only in case are considered equal
+func (d *Dataframe) Distinct(column string) ([]string, error) {
+	cid := slices.IndexFunc(d.Columns, func(c Column) bool {
+		return d.sameName(c.name, column)
+	})
+	if cid < 0 {
+		return nil, &errors.ColumnsNotFoundErr{Available: d.Header(), Required: []string{column}}
+	}
+	seen := make(map[string]struct{})
+	values := make([]string, 0)
+	for _, r := range d.Rows {
+		v := r[d.Columns[cid].idx]
+		if _, ok := seen[strings.ToLower(v)]; ok {
+			continue
+		}
+		seen[strings.ToLower(v)] = struct{}{}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+// MapColumn replaces every value of column with fn(value) in place
+func (d *Dataframe) MapColumn(column string, fn func(string) string) error {
+	cid := slices.IndexFunc(d.Columns, func(c Column) bool {
+		return d.sameName(c.name, column)
+	})
+	if cid < 0 {
+		return &errors.ColumnsNotFoundErr{Available: d.Header(), Required: []string{column}}
+	}
+	content := make([]string, len(d.Rows))
+	for idx, r := range d.Rows {
+		r[d.Columns[cid].idx] = fn(r[d.Columns[cid].idx])
+		content[idx] = r[d.Columns[cid].idx]
+	}
+	d.Columns[cid].content = content
+	return nil
+}
+
+// UpperColumn uppercases every value of column; see MapColumn
+func (d *Dataframe) UpperColumn(column string) error {
+	return d.MapColumn(column, strings.ToUpper)
+}
+
+// LowerColumn lowercases every value of column; see MapColumn
+func (d *Dataframe) LowerColumn(column string) error {
+	return d.MapColumn(column, strings.ToLower)
+}
+
+// TrimColumn removes the leading and trailing white space of every value of column; see MapColumn
+func (d *Dataframe) TrimColumn(column string) error {
+	return d.MapColumn(column, strings.TrimSpace)
+}
+
+// DistinctCount returns the number of unique values in column; see Distinct
+func (d *Dataframe) DistinctCount(column string) (int, error) {
+	values, err := d.Distinct(column)
+	if err != nil {
+		return 0, err
+	}
+	return len(values), nil
+}
+
+// excelEpoch is day zero of the Excel 1900 date system, adjusted for the non-existent 1900-02-29
+var excelEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// ParseDateColumn rewrites the cells of column as RFC3339 dates (2006-01-02); each cell is tried against layouts in order
+// and then as an Excel serial date; empty cells are left as they are and unparseable cells are reported in a *CellParseErr
+func (d *Dataframe) ParseDateColumn(column string, layouts []string) error {
+	cid := slices.IndexFunc(d.Columns, func(c Column) bool {
+		return d.sameName(c.name, column)
+	})
+	if cid < 0 {
+		return &errors.ColumnsNotFoundErr{Available: d.Header(), Required: []string{column}}
+	}
+	failed := &errors.CellParseErr{Column: d.Columns[cid].name}
+	for idx, r := range d.Rows {
+		v := strings.TrimSpace(r[d.Columns[cid].idx])
+		if len(v) == 0 {
+			continue
+		}
+		t, ok := parseDate(v, layouts, true)
+		if !ok {
+			failed.Rows = append(failed.Rows, idx)
+			failed.Values = append(failed.Values, v)
+			continue
+		}
+		r[d.Columns[cid].idx] = t.Format(time.DateOnly)
+	}
+	d.fillContent()
+	if len(failed.Rows) > 0 {
+		return failed
+	}
+	return nil
+}
+
+func parseDate(v string, layouts []string, excelSerial bool) (time.Time, bool) {
+	for _, l := range layouts {
+		if t, err := time.Parse(l, v); err == nil {
+			return t, true
+		}
+	}
+	if !excelSerial {
+		return time.Time{}, false
+	}
+	// Excel serial dates; anything outside 1900-01-01..9999-12-31 is not a date
+	serial, err := strconv.ParseFloat(v, 64)
+	if err != nil || serial < 1 || serial > 2958465 {
+		return time.Time{}, false
+	}
+	return excelEpoch.Add(time.Duration(serial * float64(24*time.Hour))), true
+}
+
+func (d *Dataframe) clean() error {
 	dfWidth := len(d.Columns)
 	cleanRecords := make([]Record, 0)
-	for _, r := range d.Rows {
+	mismatch := &errors.RowWidthErr{Width: dfWidth}
+	for idx, r := range d.Rows {
 		// we want all records to be with the same length as the dataframe header AND sometimes we have headers in the middle of our files :)
-		if len(r) == dfWidth && !strings.EqualFold(d.Header()[0], cleanRecord(r)[0]) {
+		if len(r) != dfWidth {
+			mismatch.Rows = append(mismatch.Rows, idx)
+			mismatch.Widths = append(mismatch.Widths, len(r))
+			continue
+		}
+		if !strings.EqualFold(d.Header()[0], d.normalizeName(d.cleanRecord(r)[0])) {
 			cleanRecords = append(cleanRecords, r)
 		}
-		fmt.Printf("removing record:length(%d)-required(%s)", len(r), dfWidth)
+	}
+	if len(mismatch.Rows) > 0 {
+		if d.strictWidth {
+			return mismatch
+		}
+		d.warn("removed records with a length other than the header width", "count", len(mismatch.Rows), "width", dfWidth)
 	}
 	d.Rows = cleanRecords
+	d.fillContent()
+	return nil
+}
+
+// fillContent caches every column's values in Column.content so GetColumn does not rescan the rows
+func (d *Dataframe) fillContent() {
+	for cid := range d.Columns {
+		content := make([]string, len(d.Rows))
+		for idx, r := range d.Rows {
+			// rows from WithCleanerFunc or edited through d.Rows are not width checked; a missing cell reads as empty
+			if d.Columns[cid].idx < len(r) {
+				content[idx] = r[d.Columns[cid].idx]
+			}
+		}
+		d.Columns[cid].content = content
+	}
 }
 
 func NewDataframe(opts ...DfOpt) (*Dataframe, error) {
 	df := new(Dataframe)
+	var errs []error
 	for _, opt := range opts {
 		err := opt(df)
-		// TODO: Should we quit dataframe construction and return if a dataframe opt fails?
 		if err != nil {
-			return nil, err
+			if !df.collectErrors {
+				return nil, err
+			}
+			errs = append(errs, err)
 		}
 	}
+	if len(errs) > 0 {
+		// the frame is left as the opts built it; cleaning a frame with failed opts would only add noise
+		return df, stdErrors.Join(errs...)
+	}
 	if !df.cleaned {
-		df.clean()
+		if err := df.clean(); err != nil {
+			return nil, err
+		}
+	} else {
+		df.fillContent()
 	}
 	return df, nil
 }
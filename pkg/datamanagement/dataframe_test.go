@@ -0,0 +1,626 @@
+package datamanagement
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ivanehh/boiler/internal/helpers/errors"
+	"github.com/ivanehh/boiler/pkg/logging"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestWithRecordsFromTextTrailingNewline(t *testing.T) {
+	cases := map[string]string{
+		"no trailing newline":   "date,value\n2024-01-01,1\n2024-01-02,2",
+		"trailing newline":      "date,value\n2024-01-01,1\n2024-01-02,2\n",
+		"trailing CRLF":         "date,value\r\n2024-01-01,1\r\n2024-01-02,2\r\n",
+		"several blank endings": "date,value\n2024-01-01,1\n2024-01-02,2\n\n\n",
+	}
+	for name, text := range cases {
+		t.Run(name, func(t *testing.T) {
+			d, err := NewDataframe(WithRecordsFromText([]byte(text), "\n", ","), WithInterpretedColumns(), WithStrictWidth())
+			require.NoError(t, err)
+			assert.Equal(t, []string{"date", "value"}, d.Header())
+			assert.Equal(t, []Record{{"2024-01-01", "1"}, {"2024-01-02", "2"}}, d.Rows)
+		})
+	}
+}
+
+func TestWithInterpretedColumnsDuplicateHeaders(t *testing.T) {
+	d, err := NewDataframe(WithRecordsFromText([]byte("Date,Amount,Amount,amount_2\n2024-01-01,1,2,3"), "\n", ","), WithInterpretedColumns())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"date", "amount", "amount_3", "amount_2"}, d.Header())
+}
+
+func TestWithCaseSensitiveColumns(t *testing.T) {
+	d, err := NewDataframe(WithCaseSensitiveColumns(), WithRecordsFromText([]byte("Date,kg,Kg\n2024-01-01,1,2"), "\n", ","), WithInterpretedColumns())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Date", "kg", "Kg"}, d.Header())
+	row, err := d.Get(0, "Kg")
+	require.NoError(t, err)
+	assert.Equal(t, []Record{{"2"}}, row.Rows)
+}
+
+func TestDfRowsAsStructListTypedFields(t *testing.T) {
+	type row struct {
+		Date   time.Time `df:"date,02.01.2006"`
+		Count  int       `df:"count"`
+		Total  int64     `df:"total"`
+		Active bool      `df:"active"`
+		Price  float64   `df:"price"`
+		Name   string    `df:"name"`
+	}
+	d, err := NewDataframe(WithRecordsFromText([]byte("date,count,total,active,price,name\n15.01.2024,3,40,true,1.5,a"), "\n", ","), WithInterpretedColumns())
+	require.NoError(t, err)
+	rows, err := DfRowsAsStructList[row](d)
+	require.NoError(t, err)
+	assert.Equal(t, []row{{Date: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), Count: 3, Total: 40, Active: true, Price: 1.5, Name: "a"}}, rows)
+
+	d.Rows[0][1] = "three"
+	_, err = DfRowsAsStructList[row](d)
+	assert.ErrorContains(t, err, "column count row 0")
+}
+
+func TestNewDataframeFromStructsRoundTrip(t *testing.T) {
+	type row struct {
+		Date    time.Time `df:"date,02.01.2006"`
+		Count   int       `df:"count"`
+		Price   float64   `df:"price"`
+		Ignored string    `df:"-"`
+		// unexported fields cannot be read through reflect and are skipped despite the tag
+		note time.Time `df:"note"`
+	}
+	items := []row{{Date: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), Count: 3, Price: 1.50}}
+	d, err := NewDataframeFromStructs(items)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"date", "count", "price"}, d.Header())
+	assert.Equal(t, []Record{{"15.01.2024", "3", "1.5"}}, d.Rows)
+
+	back, err := DfRowsAsStructList[row](d)
+	require.NoError(t, err)
+	assert.Equal(t, items, back)
+
+	empty, err := NewDataframeFromStructs([]row{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"date", "count", "price"}, empty.Header())
+	assert.Empty(t, empty.Rows)
+}
+
+func TestNewDataframeFromStructsFloat32(t *testing.T) {
+	type row struct {
+		Weight float32 `df:"weight"`
+	}
+	d, err := NewDataframeFromStructs([]row{{Weight: 0.1}})
+	require.NoError(t, err)
+	assert.Equal(t, []Record{{"0.1"}}, d.Rows)
+}
+
+func TestWriteCSV(t *testing.T) {
+	d, err := NewDataframe(WithRecordsFromText([]byte("date,note\n2024-01-01,plain\n2024-01-02,x"), "\n", ","), WithInterpretedColumns())
+	require.NoError(t, err)
+	d.Rows[1][1] = "has, \"quotes\"\nand lines"
+	var b bytes.Buffer
+	require.NoError(t, d.WriteCSV(&b, ","))
+	assert.Equal(t, "date,note\r\n2024-01-01,plain\r\n2024-01-02,\"has, \"\"quotes\"\"\nand lines\"\r\n", b.String())
+
+	d.Rows[0] = append(d.Rows[0], "extra")
+	assert.Error(t, d.WriteCSV(&b, ","))
+}
+
+func TestWithRecordsFromTextQuoting(t *testing.T) {
+	cases := map[string]struct {
+		text string
+		want []Record
+	}{
+		"embedded separator": {
+			text: "date,address\n2024-01-01,\"Main St 1, Springfield\"",
+			want: []Record{{"2024-01-01", "Main St 1, Springfield"}},
+		},
+		"embedded newline": {
+			text: "date,note\r\n2024-01-01,\"first line\r\nsecond line\"\r\n",
+			want: []Record{{"2024-01-01", "first line\nsecond line"}},
+		},
+		"escaped quotes": {
+			text: "date,note\n2024-01-01,\"say \"\"hi\"\"\"",
+			want: []Record{{"2024-01-01", `say "hi"`}},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			d, err := NewDataframe(WithRecordsFromText([]byte(tc.text), "\n", ","), WithInterpretedColumns(), WithStrictWidth())
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, d.Rows)
+		})
+	}
+}
+
+func TestGetColumn(t *testing.T) {
+	d, err := NewDataframe(WithRecordsFromText([]byte("name,qty\na,1\nb,2"), "\n", ","), WithInterpretedColumns())
+	require.NoError(t, err)
+	values, err := d.GetColumn("QTY")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1", "2"}, values)
+	require.NoError(t, d.UpperColumn("name"))
+	values, err = d.GetColumn("name")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"A", "B"}, values)
+	_, err = d.GetColumn("missing")
+	var notFound *errors.ColumnsNotFoundErr
+	assert.ErrorAs(t, err, &notFound)
+}
+
+func TestGetColumnAfterRowChanges(t *testing.T) {
+	d, err := NewDataframe(WithRecordsFromText([]byte("name,qty\na,1\nb,2\nc,3"), "\n", ","), WithInterpretedColumns())
+	require.NoError(t, err)
+	require.NoError(t, d.Drop(0))
+	values, err := d.GetColumn("qty")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2", "3"}, values)
+	row, err := d.Get(1, "qty")
+	require.NoError(t, err)
+	values, err = row.GetColumn("qty")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"3"}, values)
+
+	type item struct {
+		Qty int `df:"qty"`
+	}
+	d, err = NewDataframeFromStructs([]item{{Qty: 4}})
+	require.NoError(t, err)
+	values, err = d.GetColumn("qty")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"4"}, values)
+
+	d, err = NewDataframe(WithRecordsFromText([]byte("date\n15.01.2024"), "\n", ","), WithInterpretedColumns())
+	require.NoError(t, err)
+	d.Rows = append(d.Rows, Record{"16.01.2024"})
+	require.NoError(t, d.ParseDateColumn("date", []string{"02.01.2006"}))
+	values, err = d.GetColumn("date")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2024-01-15", "2024-01-16"}, values)
+
+	ragged := func(*Dataframe) ([]Record, error) {
+		return []Record{{"a", "1"}, {"b"}}, nil
+	}
+	d, err = NewDataframe(WithRecordsFromText([]byte("name,qty"), "\n", ","), WithInterpretedColumns(), WithCleanerFunc(ragged))
+	require.NoError(t, err)
+	values, err = d.GetColumn("qty")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1", ""}, values)
+}
+
+func TestWritePartitioned(t *testing.T) {
+	d, err := NewDataframe(WithRecordsFromText([]byte("plant,qty\nsofia,1\nplovdiv,2\nsofia,3\n,4"), "\n", ","), WithInterpretedColumns())
+	require.NoError(t, err)
+	dir := t.TempDir()
+	require.NoError(t, d.WritePartitioned(dir, "PLANT", func(w io.Writer, part *Dataframe) error {
+		return part.WriteCSV(w, ",")
+	}))
+	got, err := os.ReadFile(filepath.Join(dir, "sofia"))
+	require.NoError(t, err)
+	assert.Equal(t, "plant,qty\r\nsofia,1\r\nsofia,3\r\n", string(got))
+	for _, name := range []string{"plovdiv", "_empty"} {
+		assert.FileExists(t, filepath.Join(dir, name))
+	}
+}
+
+func TestWritePartitionedCollisions(t *testing.T) {
+	write := func(w io.Writer, part *Dataframe) error {
+		return part.WriteCSV(w, ",")
+	}
+	// the cutset keeps loading from trimming "sofia " so that it reaches WritePartitioned as a distinct value
+	for _, text := range []string{"plant\na/b\na_b", "plant\nsofia \nsofia"} {
+		d, err := NewDataframe(WithTrimCutset("\x00"), WithRecordsFromText([]byte(text), "\n", ","), WithInterpretedColumns())
+		require.NoError(t, err)
+		dir := t.TempDir()
+		assert.ErrorIs(t, d.WritePartitioned(dir, "plant", write), ErrPartitionCollision)
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	}
+
+	d, err := NewDataframe(WithRecordsFromText([]byte("plant\nsofia"), "\n", ","), WithInterpretedColumns())
+	require.NoError(t, err)
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "sofia"), []byte("kept"), 0o644))
+	assert.ErrorIs(t, d.WritePartitioned(dir, "plant", write), os.ErrExist)
+	got, err := os.ReadFile(filepath.Join(dir, "sofia"))
+	require.NoError(t, err)
+	assert.Equal(t, "kept", string(got))
+}
+
+func TestDrop(t *testing.T) {
+	d, err := NewDataframe(WithRecordsFromText([]byte("n\n0\n1\n2\n3\n4"), "\n", ","), WithInterpretedColumns())
+	require.NoError(t, err)
+	require.NoError(t, d.Drop(3, 1, 3))
+	assert.Equal(t, []Record{{"0"}, {"2"}, {"4"}}, d.Rows)
+	require.NoError(t, d.Drop(2))
+	assert.Equal(t, []Record{{"0"}, {"2"}}, d.Rows)
+	assert.ErrorIs(t, d.Drop(0, 2), ErrRowOutOfRange)
+	assert.Len(t, d.Rows, 2)
+}
+
+func TestDropColumnsAndRenameColumn(t *testing.T) {
+	d, err := NewDataframe(WithRecordsFromText([]byte("a,b,c,d\n1,2,3,4\n5,6,7,8"), "\n", ","), WithInterpretedColumns())
+	require.NoError(t, err)
+	require.NoError(t, d.DropColumns("B", "d", "b"))
+	assert.Equal(t, []string{"a", "c"}, d.Header())
+	assert.Equal(t, []Record{{"1", "3"}, {"5", "7"}}, d.Rows)
+	require.NoError(t, d.RenameColumn("C", "total"))
+	row, err := d.Get(1, "total")
+	require.NoError(t, err)
+	assert.Equal(t, []Record{{"7"}}, row.Rows)
+	var notFound *errors.ColumnsNotFoundErr
+	assert.ErrorAs(t, d.DropColumns("missing"), &notFound)
+	assert.ErrorAs(t, d.RenameColumn("missing", "x"), &notFound)
+}
+
+func TestDropColumnsShortRow(t *testing.T) {
+	d, err := NewDataframe(WithRecordsFromText([]byte("a,b,c,d\n1,2,3,4"), "\n", ","), WithInterpretedColumns())
+	require.NoError(t, err)
+	// rows edited through d.Rows are not width checked
+	d.Rows = append(d.Rows, Record{"5"})
+	require.NoError(t, d.DropColumns("b", "c", "d"))
+	assert.Equal(t, []Record{{"1"}, {"5"}}, d.Rows)
+}
+
+func TestRename(t *testing.T) {
+	d, err := NewDataframe(WithRecordsFromText([]byte("Date,Qty,Plant\n2024-01-01,1,sofia"), "\n", ","), WithInterpretedColumns())
+	require.NoError(t, err)
+	require.NoError(t, d.Rename(map[string]string{"QTY": "Amount", "plant": "site"}))
+	assert.Equal(t, []string{"date", "amount", "site"}, d.Header())
+	assert.ErrorIs(t, d.Rename(map[string]string{"DATE": "site"}), ErrColumnCollision)
+	var notFound *errors.ColumnsNotFoundErr
+	assert.ErrorAs(t, d.Rename(map[string]string{"qty": "x"}), &notFound)
+	assert.Equal(t, []string{"date", "amount", "site"}, d.Header())
+}
+
+func TestSortBy(t *testing.T) {
+	d, err := NewDataframe(WithRecordsFromText([]byte("plant,qty\nb,10\na,9\nb,2\na,10"), "\n", ","), WithInterpretedColumns())
+	require.NoError(t, err)
+	require.NoError(t, d.SortBy("PLANT", "qty"))
+	assert.Equal(t, []Record{{"a", "10"}, {"a", "9"}, {"b", "10"}, {"b", "2"}}, d.Rows)
+	require.NoError(t, d.SortByFunc("qty", func(a, b string) bool {
+		x, _ := strconv.Atoi(a)
+		y, _ := strconv.Atoi(b)
+		return x < y
+	}))
+	assert.Equal(t, []Record{{"b", "2"}, {"a", "9"}, {"a", "10"}, {"b", "10"}}, d.Rows)
+	assert.Equal(t, []string{"plant", "qty"}, d.Header())
+	var notFound *errors.ColumnsNotFoundErr
+	assert.ErrorAs(t, d.SortBy("plant", "missing"), &notFound)
+}
+
+func TestWithRecordsFromFilesFormats(t *testing.T) {
+	want := []Record{{"2024-01-01", "sofia", "12"}, {"2024-01-02", "plovdiv", "7"}}
+	for _, fp := range []string{"testdata/records.csv", "testdata/records.xlsx"} {
+		t.Run(filepath.Ext(fp), func(t *testing.T) {
+			d, err := NewDataframe(WithRecordsFromFiles([]string{fp}), WithInterpretedColumns())
+			require.NoError(t, err)
+			assert.Equal(t, []string{"date", "plant", "qty"}, d.Header())
+			assert.Equal(t, want, d.Rows)
+		})
+	}
+	d, err := NewDataframe(WithRecordsFromFiles([]string{"testdata/records.csv", "testdata/records.xlsx"}), WithInterpretedColumns())
+	require.NoError(t, err)
+	assert.Equal(t, append(slices.Clone(want), want...), d.Rows)
+}
+
+func TestWithRecordsFromSheet(t *testing.T) {
+	fp := filepath.Join(t.TempDir(), "book.xlsx")
+	f := excelize.NewFile()
+	require.NoError(t, f.SetSheetName("Sheet1", "Summary"))
+	require.NoError(t, f.SetSheetRow("Summary", "A1", &[]any{"date", "total"}))
+	_, err := f.NewSheet("Data")
+	require.NoError(t, err)
+	require.NoError(t, f.SetSheetRow("Data", "A1", &[]any{"date", "plant"}))
+	require.NoError(t, f.SetSheetRow("Data", "A2", &[]any{"2024-01-01", "sofia"}))
+	require.NoError(t, f.SaveAs(fp))
+	require.NoError(t, f.Close())
+
+	for name, opt := range map[string]DfOpt{"by name": WithRecordsFromSheet([]string{fp}, "Data"), "by index": WithRecordsFromSheetIndex([]string{fp}, 1)} {
+		t.Run(name, func(t *testing.T) {
+			d, err := NewDataframe(opt, WithInterpretedColumns())
+			require.NoError(t, err)
+			assert.Equal(t, []string{"date", "plant"}, d.Header())
+			assert.Equal(t, []Record{{"2024-01-01", "sofia"}}, d.Rows)
+		})
+	}
+	_, err = NewDataframe(WithRecordsFromSheet([]string{fp}, "Missing"), WithInterpretedColumns())
+	var notFound *errors.SheetNotFoundErr
+	require.ErrorAs(t, err, &notFound)
+	assert.ElementsMatch(t, []string{"Summary", "Data"}, notFound.Available)
+}
+
+func TestConcat(t *testing.T) {
+	first, err := NewDataframe(WithRecordsFromText([]byte("date,qty\n2024-01-01,1"), "\n", ","), WithInterpretedColumns())
+	require.NoError(t, err)
+	second, err := NewDataframe(WithRecordsFromText([]byte("date,qty\n2024-01-02,2"), "\n", ","), WithInterpretedColumns())
+	require.NoError(t, err)
+	d, err := Concat(first, second, second)
+	require.NoError(t, err)
+	assert.Equal(t, []Record{{"2024-01-01", "1"}, {"2024-01-02", "2"}, {"2024-01-02", "2"}}, d.Rows)
+	assert.Len(t, first.Rows, 1)
+
+	other, err := NewDataframe(WithRecordsFromText([]byte("qty,date\n3,2024-01-03"), "\n", ","), WithInterpretedColumns())
+	require.NoError(t, err)
+	var mismatch *errors.HeaderMismatchErr
+	require.ErrorAs(t, first.Append(other), &mismatch)
+	assert.Equal(t, []string{"date", "qty"}, mismatch.Original)
+	assert.Equal(t, []string{"qty", "date"}, mismatch.Mismatch)
+}
+
+func TestGroupBySum(t *testing.T) {
+	d, err := NewDataframe(WithRecordsFromText([]byte("plant,qty\nsofia,1.5\nplovdiv,2\nsofia,\nsofia,3"), "\n", ","), WithInterpretedColumns())
+	require.NoError(t, err)
+	groups, err := d.GroupBy("Plant")
+	require.NoError(t, err)
+	require.Len(t, groups, 2)
+	assert.Equal(t, 3, groups["sofia"].Count())
+	sum, err := groups["sofia"].Sum("qty")
+	require.NoError(t, err)
+	assert.Equal(t, 4.5, sum)
+
+	_, err = d.Sum("plant")
+	var parseErr *errors.CellParseErr
+	require.ErrorAs(t, err, &parseErr)
+	assert.Equal(t, []string{"sofia"}, parseErr.Values)
+}
+
+func TestWithTrimCutset(t *testing.T) {
+	fp := filepath.Join(t.TempDir(), "values.csv")
+	require.NoError(t, os.WriteFile(fp, []byte("date,value\n 2024-01-01 ,-12.5\n2024-01-02,+3\n"), 0o644))
+	d, err := NewDataframe(WithRecordsFromFiles([]string{fp}), WithInterpretedColumns())
+	require.NoError(t, err)
+	assert.Equal(t, []Record{{"2024-01-01", "-12.5"}, {"2024-01-02", "+3"}}, d.Rows)
+
+	d, err = NewDataframe(WithTrimCutset(" +"), WithRecordsFromFiles([]string{fp}), WithInterpretedColumns())
+	require.NoError(t, err)
+	assert.Equal(t, []Record{{"2024-01-01", "-12.5"}, {"2024-01-02", "3"}}, d.Rows)
+}
+
+func TestWithRecordsFromFilesSparseRows(t *testing.T) {
+	fp := filepath.Join(t.TempDir(), "sparse.csv")
+	require.NoError(t, os.WriteFile(fp, []byte("date,plant,qty\n2024-01-01,,3\n,,\n2024-01-02,sofia,\n,,\n"), 0o644))
+	d, err := NewDataframe(WithRecordsFromFiles([]string{fp}), WithInterpretedColumns())
+	require.NoError(t, err)
+	assert.Equal(t, []Record{{"2024-01-01", "", "3"}, {"", "", ""}, {"2024-01-02", "sofia", ""}}, d.Rows)
+
+	var stat LoadStat
+	d, err = NewDataframe(WithLoadMetrics(func(s LoadStat) { stat = s }), WithDropEmptyRows(), WithRecordsFromFiles([]string{fp}), WithInterpretedColumns())
+	require.NoError(t, err)
+	assert.Equal(t, []Record{{"2024-01-01", "", "3"}, {"2024-01-02", "sofia", ""}}, d.Rows)
+	assert.Equal(t, 2, stat.Rejects)
+
+	// the limit is reached while the held back blank rows are added; the one left out is still counted
+	fp = filepath.Join(t.TempDir(), "blank.csv")
+	require.NoError(t, os.WriteFile(fp, []byte("date,plant,qty\n2024-01-01,,3\n,,\n,,\n2024-01-02,sofia,\n"), 0o644))
+	d, err = NewDataframe(WithLoadMetrics(func(s LoadStat) { stat = s }), WithMaxRows(2), WithRecordsFromFiles([]string{fp}), WithInterpretedColumns())
+	require.NoError(t, err)
+	assert.Equal(t, []Record{{"2024-01-01", "", "3"}, {"", "", ""}}, d.Rows)
+	assert.Equal(t, 3, stat.Rows)
+	assert.Equal(t, 1, stat.Rejects)
+
+	fp = filepath.Join(t.TempDir(), "leading.csv")
+	require.NoError(t, os.WriteFile(fp, []byte(",,\n,,\ndate,plant,qty\n2024-01-01,sofia,3\n"), 0o644))
+	d, err = NewDataframe(WithLoadMetrics(func(s LoadStat) { stat = s }), WithRecordsFromFiles([]string{fp}), WithInterpretedColumns())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"date", "plant", "qty"}, d.Header())
+	assert.Equal(t, []Record{{"2024-01-01", "sofia", "3"}}, d.Rows)
+	assert.Equal(t, 2, stat.Rejects)
+}
+
+func TestOpenDataframeStream(t *testing.T) {
+	want := []Record{{"date", "plant", "qty"}, {"2024-01-01", "sofia", "12"}, {"2024-01-02", "plovdiv", "7"}}
+	for _, fp := range []string{"testdata/records.csv", "testdata/records.xlsx"} {
+		t.Run(filepath.Ext(fp), func(t *testing.T) {
+			assert.Equal(t, want, streamAll(t, fp))
+		})
+	}
+}
+
+func TestOpenDataframeStreamOpts(t *testing.T) {
+	fp := filepath.Join(t.TempDir(), "records.csv")
+	// a UTF-8 BOM followed by windows-1252 text, where 0xF6 is ö
+	require.NoError(t, os.WriteFile(fp, []byte("\xEF\xBB\xBFdate,plant\n2024-01-01, K\xF6ln \n"), 0o644))
+	assert.Equal(t, []Record{{"date", "plant"}, {"2024-01-01", "Köln"}}, streamAll(t, fp, WithEncoding("windows-1252"), WithTrimCutset(" ")))
+}
+
+func TestOpenDataframeStreamDelimiters(t *testing.T) {
+	dir := t.TempDir()
+	quoted := filepath.Join(dir, "quoted.csv")
+	require.NoError(t, os.WriteFile(quoted, []byte("name,plant,date\n\"Smith, John\",Sofia,2024-01-01\n"), 0o644))
+	assert.Equal(t, []Record{{"name", "plant", "date"}, {"Smith, John", "Sofia", "2024-01-01"}}, streamAll(t, quoted))
+
+	// a tab separated .txt must stream like WithRecordsFromFiles loads it
+	var text strings.Builder
+	text.WriteString("date\tplant\tqty\n")
+	for day := 1; day <= 12; day++ {
+		fmt.Fprintf(&text, "2024-01-%02d\tsofia\t%d\n", day, day)
+	}
+	tabbed := filepath.Join(dir, "tabbed.txt")
+	require.NoError(t, os.WriteFile(tabbed, []byte(text.String()), 0o644))
+	d, err := NewDataframe(WithRecordsFromFiles([]string{tabbed}), WithInterpretedColumns())
+	require.NoError(t, err)
+	got := streamAll(t, tabbed)
+	assert.Equal(t, Record(d.Header()), got[0])
+	assert.Equal(t, d.Rows, got[1:])
+}
+
+func streamAll(t *testing.T, fp string, opts ...DfOpt) []Record {
+	t.Helper()
+	s, err := OpenDataframeStream(fp, opts...)
+	require.NoError(t, err)
+	defer s.Close()
+	var got []Record
+	for r, ok := s.Next(); ok; r, ok = s.Next() {
+		got = append(got, r)
+	}
+	require.NoError(t, s.Err())
+	return got
+}
+
+func TestWithInterpretedColumnsNoRows(t *testing.T) {
+	fp := filepath.Join(t.TempDir(), "empty.csv")
+	require.NoError(t, os.WriteFile(fp, nil, 0o644))
+	for name, opt := range map[string]DfOpt{"file": WithRecordsFromFiles([]string{fp}), "text": WithRecordsFromText(nil, "\n", ",")} {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewDataframe(opt, WithInterpretedColumns())
+			assert.ErrorIs(t, err, ErrNoRows)
+		})
+	}
+}
+
+func TestDropDuplicates(t *testing.T) {
+	d, err := NewDataframe(WithRecordsFromText([]byte("date,plant,qty\n2024-01-01,sofia,1\n2024-01-01,sofia,1\n2024-01-01,sofia,2\n2024-01-02,sofia,1"), "\n", ","), WithInterpretedColumns())
+	require.NoError(t, err)
+	removed, err := d.DropDuplicates()
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+	removed, err = d.DropDuplicates("DATE", "plant")
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+	assert.Equal(t, []Record{{"2024-01-01", "sofia", "1"}, {"2024-01-02", "sofia", "1"}}, d.Rows)
+	_, err = d.DropDuplicates("missing")
+	var notFound *errors.ColumnsNotFoundErr
+	assert.ErrorAs(t, err, &notFound)
+}
+
+func TestDescribe(t *testing.T) {
+	d, err := NewDataframe(WithRecordsFromText([]byte("plant,qty\nsofia,-2\nsofia,n/a\nsofia,\nsofia,8"), "\n", ","), WithInterpretedColumns())
+	require.NoError(t, err)
+	stats, err := d.Describe("QTY")
+	require.NoError(t, err)
+	assert.Equal(t, ColumnStats{Count: 2, Min: -2, Max: 8, Mean: 3, NonNumeric: 1}, stats)
+	_, err = d.Describe("missing")
+	var notFound *errors.ColumnsNotFoundErr
+	assert.ErrorAs(t, err, &notFound)
+}
+
+func TestDfRowsAsStructListHeaderNames(t *testing.T) {
+	type row struct {
+		OrderDate string `df:"order date"`
+		Plant     string `df:"Plant_Name"`
+		Qty       int    `df:"QTY"`
+	}
+	cases := map[string]DfOpt{
+		"interpreted": WithInterpretedColumns(),
+		"provided":    WithProvidedColumns([]string{"Order Date", "plant_name", "Qty"}),
+	}
+	for name, columns := range cases {
+		t.Run(name, func(t *testing.T) {
+			d, err := NewDataframe(WithRecordsFromText([]byte("Order Date,plant_name,Qty\n2024-01-01,sofia,3"), "\n", ","), columns)
+			require.NoError(t, err)
+			rows, err := DfRowsAsStructList[row](d)
+			require.NoError(t, err)
+			assert.Equal(t, []row{{OrderDate: "2024-01-01", Plant: "sofia", Qty: 3}}, rows)
+		})
+	}
+}
+
+func TestWithLoggerWidthMismatch(t *testing.T) {
+	var out bytes.Buffer
+	d, err := NewDataframe(WithLogger(logging.New(logging.LoggerConfig{Level: logging.WarnLevel, Output: &out})), WithRecordsFromText([]byte("date,qty\n2024-01-01,1\n2024-01-02"), "\n", ","), WithInterpretedColumns())
+	require.NoError(t, err)
+	assert.Len(t, d.Rows, 1)
+	assert.Contains(t, out.String(), "removed records with a length other than the header width")
+}
+
+func TestWithLoggerRowLimit(t *testing.T) {
+	var out bytes.Buffer
+	d, err := NewDataframe(WithLogger(logging.New(logging.LoggerConfig{Level: logging.WarnLevel, Output: &out})), WithMaxRows(1), WithRecordsFromText([]byte("date,qty\n2024-01-01,1\n2024-01-02,2"), "\n", ","), WithInterpretedColumns())
+	require.NoError(t, err)
+	assert.True(t, d.Truncated())
+	assert.Contains(t, out.String(), "row limit reached")
+}
+
+func TestWithMaxRows(t *testing.T) {
+	text := []byte("date,qty\n2024-01-01,1\n2024-01-02,2\n2024-01-03,3")
+	for n, want := range map[int][]Record{
+		0: {{"2024-01-01", "1"}, {"2024-01-02", "2"}, {"2024-01-03", "3"}},
+		2: {{"2024-01-01", "1"}, {"2024-01-02", "2"}},
+		3: {{"2024-01-01", "1"}, {"2024-01-02", "2"}, {"2024-01-03", "3"}},
+	} {
+		t.Run(strconv.Itoa(n), func(t *testing.T) {
+			d, err := NewDataframe(WithMaxRows(n), WithRecordsFromText(text, "\n", ","), WithInterpretedColumns())
+			require.NoError(t, err)
+			assert.Equal(t, []string{"date", "qty"}, d.Header())
+			assert.Equal(t, want, d.Rows)
+			assert.Equal(t, n == 2, d.Truncated())
+		})
+	}
+}
+
+func TestPreview(t *testing.T) {
+	for n, want := range map[int]int{0: 0, 1: 1, 5: 2} {
+		d, err := Preview("testdata/records.csv", n)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"date", "plant", "qty"}, d.Header())
+		assert.Len(t, d.Rows, want)
+		assert.Equal(t, n < 2, d.Truncated())
+	}
+	_, err := Preview("testdata/records.csv", -1)
+	assert.Error(t, err)
+}
+
+func TestWithLoggerDuplicateColumns(t *testing.T) {
+	var out bytes.Buffer
+	d, err := NewDataframe(WithLogger(logging.New(logging.LoggerConfig{Level: logging.WarnLevel, Output: &out})), WithRecordsFromText([]byte("date,qty,qty\n2024-01-01,1,2"), "\n", ","), WithInterpretedColumns())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"date", "qty", "qty_2"}, d.Header())
+	assert.Contains(t, out.String(), "duplicate column renamed")
+}
+
+func TestDiff(t *testing.T) {
+	before, err := NewDataframe(WithRecordsFromText([]byte("date,plant,qty\n2024-01-01,sofia,1\n2024-01-02,sofia,2\n2024-01-03,sofia,3"), "\n", ","), WithInterpretedColumns())
+	require.NoError(t, err)
+	// changed holds as many rows as before, which must not make it report the values cached for before
+	after, err := NewDataframe(WithRecordsFromText([]byte("date,plant,qty\n2024-01-01,sofia,10\n2024-01-02,sofia,20\n2024-01-03,sofia,30\n2024-01-04,sofia,4"), "\n", ","), WithInterpretedColumns())
+	require.NoError(t, err)
+	added, removed, changed, err := before.Diff(after, []string{"date"})
+	require.NoError(t, err)
+	assert.Equal(t, []Record{{"2024-01-04", "sofia", "4"}}, added.Rows)
+	assert.Empty(t, removed.Rows)
+	qty, err := changed.GetColumn("qty")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"10", "20", "30"}, qty)
+	qty, err = added.GetColumn("qty")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"4"}, qty)
+}
+
+func TestWithEncodingSources(t *testing.T) {
+	dir := t.TempDir()
+	xlsx := filepath.Join(dir, "book.xlsx")
+	f := excelize.NewFile()
+	require.NoError(t, f.SetSheetRow("Sheet1", "A1", &[]any{"date", "plant"}))
+	require.NoError(t, f.SetSheetRow("Sheet1", "A2", &[]any{"2024-01-01", "Köln"}))
+	require.NoError(t, f.SaveAs(xlsx))
+	require.NoError(t, f.Close())
+	// 0xF6 is ö in windows-1252
+	csvFile := filepath.Join(dir, "records.csv")
+	require.NoError(t, os.WriteFile(csvFile, []byte("date,plant\n2024-01-01,K\xF6ln\n"), 0o644))
+
+	for name, opt := range map[string]DfOpt{
+		"xlsx":   WithRecordsFromFiles([]string{xlsx}),
+		"csv":    WithRecordsFromFiles([]string{csvFile}),
+		"ndjson": WithRecordsFromNDJSON([]byte("{\"date\":\"2024-01-01\",\"plant\":\"K\xF6ln\"}\n")),
+	} {
+		t.Run(name, func(t *testing.T) {
+			d, err := NewDataframe(WithEncoding("windows-1252"), opt, WithInterpretedColumns())
+			require.NoError(t, err)
+			assert.Equal(t, []Record{{"2024-01-01", "Köln"}}, d.Rows)
+		})
+	}
+}
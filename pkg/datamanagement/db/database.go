@@ -2,11 +2,14 @@ package db
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
 	"html/template"
 	"reflect"
+	"sync"
+	"time"
 )
 
 var ErrBadConfig = errors.New("the configuration provided is missing fields or has bad values in the provided fields")
@@ -47,6 +50,10 @@ type DatabaseConfig struct {
 	} `json:"credentials"`
 	/* 	 ConnectionStringTemplate example:"sqlserver://{{.Credentials.Name}}:{{.Credentials.Password}}@{{.Address}}/?database={{.Name}}" */
 	ConnectionStringTemplate *template.Template
+	// MaxOpenConns caps the shared pool; zero means unlimited
+	MaxOpenConns int `json:"maxOpenConns"`
+	// AcquireTimeout bounds waiting for a pooled connection together with running the statement; zero means no timeout
+	AcquireTimeout time.Duration `json:"acquireTimeout"`
 }
 
 type Database struct {
@@ -55,6 +62,20 @@ type Database struct {
 	connString string
 	prepStmts  map[string]*sql.Stmt
 	open       bool
+	mu         sync.Mutex
+}
+
+// RedactedDSN renders the connection string with the password replaced by ****; safe for logs and error messages
+func (c DatabaseConfig) RedactedDSN() string {
+	if c.ConnectionStringTemplate == nil {
+		return ""
+	}
+	c.Credentials.Password = "****"
+	dsn := bytes.NewBuffer([]byte{})
+	if err := c.ConnectionStringTemplate.Execute(dsn, c); err != nil {
+		return ""
+	}
+	return dsn.String()
 }
 
 func ValidateConfig(c DatabaseConfig) error {
@@ -74,25 +95,53 @@ func NewDatabase(c DatabaseConfig, name string) (*Database, error) {
 	db.Config = c
 	err := db.Config.ConnectionStringTemplate.Execute(connectionString, db.Config)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("connection string rendering failed:%w", err)
 	}
 	db.connString = connectionString.String()
 
 	return nil, errors.New("no compatible source found")
 }
 
+// NewDatabaseFromDSN opens a pool for an already rendered connection string and pings it; no template or credentials are required
+func NewDatabaseFromDSN(driver, dsn string) (*Database, error) {
+	if len(driver) == 0 || len(dsn) == 0 {
+		return nil, ErrBadConfig
+	}
+	db := &Database{Config: DatabaseConfig{Driver: driver}, connString: dsn}
+	if err := db.Open(); err != nil {
+		return nil, err
+	}
+	if err := db.db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to reach %s database:%w", driver, err)
+	}
+	return db, nil
+}
+
+// Open opens the shared connection pool; it is a no-op if the pool is already open
 func (pdb *Database) Open() error {
+	pdb.mu.Lock()
+	defer pdb.mu.Unlock()
+	if pdb.open {
+		return nil
+	}
 	var err error
 	pdb.db, err = sql.Open(pdb.Config.Driver, pdb.connString)
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to open %s:%w", pdb.Config.RedactedDSN(), err)
 	}
+	pdb.db.SetMaxOpenConns(pdb.Config.MaxOpenConns)
 	pdb.open = true
 	pdb.prepStmts = make(map[string]*sql.Stmt)
 	return nil
 }
 
 func (pdb *Database) Close() error {
+	pdb.mu.Lock()
+	defer pdb.mu.Unlock()
+	if !pdb.open {
+		return nil
+	}
 	err := pdb.db.Close()
 	if err != nil {
 		return err
@@ -101,25 +150,49 @@ func (pdb *Database) Close() error {
 	return nil
 }
 
-func (pdb *Database) Query(qc Query, params ...any) (QueryUnwrapper, error) {
-	var stmt *sql.Stmt
-	var ok bool
-	var err error
-	err = pdb.Open()
-	defer pdb.Close()
+// Stats returns the statistics of the shared connection pool
+func (pdb *Database) Stats() sql.DBStats {
+	pdb.mu.Lock()
+	defer pdb.mu.Unlock()
+	if !pdb.open {
+		return sql.DBStats{}
+	}
+	return pdb.db.Stats()
+}
+
+// prepare returns the cached statement for qc, preparing it on first use
+func (pdb *Database) prepare(qc QueryConstructor) (*sql.Stmt, error) {
+	pdb.mu.Lock()
+	defer pdb.mu.Unlock()
+	if stmt, ok := pdb.prepStmts[reflect.TypeOf(qc).Name()]; ok {
+		return stmt, nil
+	}
+	stmt, err := pdb.db.Prepare(qc.Construct())
 	if err != nil {
 		return nil, err
 	}
-	defer pdb.Close()
-	if stmt, ok = pdb.prepStmts[reflect.TypeOf(qc).Name()]; !ok {
-		stmt, err = pdb.db.Prepare(qc.Construct())
-		if err != nil {
-			return nil, err
-		}
-		pdb.prepStmts[reflect.TypeOf(qc).Name()] = stmt
+	pdb.prepStmts[reflect.TypeOf(qc).Name()] = stmt
+	return stmt, nil
+}
+
+func (pdb *Database) acquireContext() (context.Context, context.CancelFunc) {
+	if pdb.Config.AcquireTimeout > 0 {
+		return context.WithTimeout(context.Background(), pdb.Config.AcquireTimeout)
+	}
+	return context.WithCancel(context.Background())
+}
 
+func (pdb *Database) Query(qc Query, params ...any) (QueryUnwrapper, error) {
+	if err := pdb.Open(); err != nil {
+		return nil, err
+	}
+	stmt, err := pdb.prepare(qc)
+	if err != nil {
+		return nil, err
 	}
-	q, err := stmt.Query(params...)
+	ctx, cancel := pdb.acquireContext()
+	defer cancel()
+	q, err := stmt.QueryContext(ctx, params...)
 	if err != nil {
 		return nil, err
 	}
@@ -128,21 +201,14 @@ func (pdb *Database) Query(qc Query, params ...any) (QueryUnwrapper, error) {
 }
 
 func (pdb *Database) Execute(qc QueryConstructor, params ...any) (sql.Result, error) {
-	var stmt *sql.Stmt
-	var ok bool
-	var err error
-	err = pdb.Open()
-	defer pdb.Close()
-	if err != nil {
+	if err := pdb.Open(); err != nil {
 		return nil, err
 	}
-	defer pdb.Close()
-	if stmt, ok = pdb.prepStmts[reflect.TypeOf(qc).Name()]; !ok {
-		stmt, err = pdb.db.Prepare(qc.Construct())
-		if err != nil {
-			return nil, fmt.Errorf("statement construction error:%w", err)
-		}
-		pdb.prepStmts[reflect.TypeOf(qc).Name()] = stmt
+	stmt, err := pdb.prepare(qc)
+	if err != nil {
+		return nil, fmt.Errorf("statement construction error:%w", err)
 	}
-	return stmt.Exec(params...)
+	ctx, cancel := pdb.acquireContext()
+	defer cancel()
+	return stmt.ExecContext(ctx, params...)
 }
@@ -0,0 +1,65 @@
+package datamanagement
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// WritePartitioned writes one file per distinct value of partitionColumn into dir, calling write with the rows holding that value;
+// files are named after the value with path separators replaced by "_" and empty values written to "_empty";
+// partitions are written in the order of their values and dir is created if missing; see GroupBy.
+// Values sharing a file name fail with ErrPartitionCollision before anything is written and existing files are never overwritten
+func (d *Dataframe) WritePartitioned(dir, partitionColumn string, write func(w io.Writer, part *Dataframe) error) error {
+	groups, err := d.GroupBy(partitionColumn)
+	if err != nil {
+		return err
+	}
+	values := slices.Sorted(maps.Keys(groups))
+	names := make(map[string]string, len(values))
+	for _, v := range values {
+		name := partitionFileName(v)
+		if other, ok := names[name]; ok {
+			return fmt.Errorf("%w:%q and %q map to %s", ErrPartitionCollision, other, v, name)
+		}
+		names[name] = v
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	for _, v := range values {
+		if err := writePartition(filepath.Join(dir, partitionFileName(v)), groups[v], write); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writePartition(path string, part *Dataframe, write func(w io.Writer, part *Dataframe) error) error {
+	// O_EXCL keeps a partition from replacing a file left by an earlier run or one matching on a case-insensitive file system
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+	if err := write(f, part); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// partitionFileName keeps a partition value from escaping dir
+func partitionFileName(v string) string {
+	v = strings.NewReplacer("/", "_", "\\", "_", "\x00", "_").Replace(strings.TrimSpace(v))
+	switch v {
+	case "":
+		return "_empty"
+	case ".", "..":
+		return "_" + v
+	}
+	return v
+}
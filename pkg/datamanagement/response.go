@@ -0,0 +1,96 @@
+package datamanagement
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ivanehh/boiler/pkg/netcom"
+)
+
+var ErrUnsupportedContentType = errors.New("response content type cannot be loaded into a dataframe")
+
+// DataframeFromResponse reads and closes the body of resp, undoing its Content-Encoding, and builds a dataframe from it; non-2xx
+// responses yield a *netcom.HTTPError
+// The loader is picked from the Content-Type: NDJSON for application/x-ndjson and application/jsonl, TSV for text/tab-separated-values
+// and CSV for text/csv, text/plain or a missing content type; a non UTF-8 charset is transcoded. settings are applied before loading,
+// so they take the opts that must precede the loading opts (e.g. WithMaxRows, WithEncoding, WithTrimCutset, WithErrorCollection),
+// and opts are applied after loading, e.g. WithInterpretedColumns
+func DataframeFromResponse(resp *http.Response, settings []DfOpt, opts ...DfOpt) (*Dataframe, error) {
+	defer resp.Body.Close()
+	decoded, err := netcom.DecodedBody(resp)
+	if err != nil {
+		return nil, err
+	}
+	defer decoded.Close()
+	body, err := io.ReadAll(decoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, &netcom.HTTPError{StatusCode: resp.StatusCode, Body: body, Header: resp.Header}
+	}
+	var loader DfOpt
+	newLine := "\n"
+	if bytes.Contains(body, []byte("\r\n")) {
+		newLine = "\r\n"
+	}
+	mediaType := ""
+	var params map[string]string
+	if ct := resp.Header.Get("Content-Type"); len(ct) != 0 {
+		if mediaType, params, err = mime.ParseMediaType(ct); err != nil {
+			return nil, fmt.Errorf("%w:%s", ErrUnsupportedContentType, ct)
+		}
+	}
+	loaders := make([]DfOpt, 0, len(settings)+len(opts)+2)
+	if charset := params["charset"]; len(charset) != 0 && !strings.EqualFold(charset, "utf-8") {
+		loaders = append(loaders, WithEncoding(charset))
+	}
+	// the caller's settings follow the charset so that an explicit WithEncoding wins
+	loaders = append(loaders, settings...)
+	switch mediaType {
+	case "application/x-ndjson", "application/jsonl":
+		loader = WithRecordsFromNDJSON(body)
+	case "text/tab-separated-values":
+		loader = WithRecordsFromText(body, newLine, "\t")
+	case "text/csv", "text/plain", "":
+		loader = WithRecordsFromText(body, newLine, ",")
+	default:
+		return nil, fmt.Errorf("%w:%s", ErrUnsupportedContentType, mediaType)
+	}
+	loaders = append(loaders, loader)
+	return NewDataframe(append(loaders, opts...)...)
+}
+
+// DataframeFromURL fetches path with client and builds a dataframe from the response via DataframeFromResponse;
+// network errors, 429 and 5xx responses are retried up to retries times with exponential backoff starting at half a second.
+// settings and opts are applied like in DataframeFromResponse
+func DataframeFromURL(ctx context.Context, client *netcom.Client, path string, retries int, settings []DfOpt, opts ...DfOpt) (*Dataframe, error) {
+	backoff := 500 * time.Millisecond
+	for attempt := 0; ; attempt++ {
+		resp, err := client.Get(ctx, path)
+		transient := err != nil || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+		if !transient || attempt >= retries {
+			if err != nil {
+				return nil, err
+			}
+			return DataframeFromResponse(resp, settings, opts...)
+		}
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
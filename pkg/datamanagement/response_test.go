@@ -0,0 +1,104 @@
+package datamanagement
+
+import (
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ivanehh/boiler/pkg/netcom"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataframeFromResponse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/csv":
+			w.Header().Set("Content-Type", "text/csv; charset=windows-1252")
+			// 0xF6 is ö in windows-1252
+			_, _ = w.Write([]byte("date,plant\n2024-01-01,K\xF6ln\n2024-01-02,sofia\n2024-01-03,plovdiv\n"))
+		case "/ndjson":
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			_, _ = w.Write([]byte("{\"date\":\"2024-01-01\",\"qty\":3}\n"))
+		case "/gzip":
+			w.Header().Set("Content-Type", "text/csv")
+			w.Header().Set("Content-Encoding", "gzip")
+			zw := gzip.NewWriter(w)
+			_, _ = zw.Write([]byte("date,qty\n2024-01-01,3\n"))
+			_ = zw.Close()
+		case "/pdf":
+			w.Header().Set("Content-Type", "application/pdf")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	get := func(path string) *http.Response {
+		resp, err := http.Get(srv.URL + path)
+		require.NoError(t, err)
+		return resp
+	}
+
+	d, err := DataframeFromResponse(get("/csv"), nil, WithInterpretedColumns())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"date", "plant"}, d.Header())
+	assert.Equal(t, Record{"2024-01-01", "Köln"}, d.Rows[0])
+	assert.Len(t, d.Rows, 3)
+
+	// settings must reach the frame before the records are loaded
+	d, err = DataframeFromResponse(get("/csv"), []DfOpt{WithMaxRows(2)}, WithInterpretedColumns())
+	require.NoError(t, err)
+	assert.True(t, d.Truncated())
+	assert.Equal(t, []Record{{"2024-01-01", "Köln"}, {"2024-01-02", "sofia"}}, d.Rows)
+
+	d, err = DataframeFromResponse(get("/ndjson"), nil, WithInterpretedColumns())
+	require.NoError(t, err)
+	assert.Equal(t, []Record{{"2024-01-01", "3"}}, d.Rows)
+
+	// the client asks for gzip itself, so the transport leaves the body compressed
+	c := netcom.NewClient(netcom.WithBaseURL(srv.URL), netcom.WithAcceptEncoding())
+	resp, err := c.Get(context.Background(), "/gzip")
+	require.NoError(t, err)
+	d, err = DataframeFromResponse(resp, nil, WithInterpretedColumns())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"date", "qty"}, d.Header())
+	assert.Equal(t, []Record{{"2024-01-01", "3"}}, d.Rows)
+
+	_, err = DataframeFromResponse(get("/pdf"), nil)
+	assert.ErrorIs(t, err, ErrUnsupportedContentType)
+
+	_, err = DataframeFromResponse(get("/missing"), nil)
+	var httpErr *netcom.HTTPError
+	require.ErrorAs(t, err, &httpErr)
+	assert.Equal(t, http.StatusNotFound, httpErr.StatusCode)
+}
+
+func TestDataframeFromURL(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "text/csv")
+		_, _ = w.Write([]byte("date,qty\n2024-01-01,1\n2024-01-02,2\n2024-01-03,3\n"))
+	}))
+	defer srv.Close()
+	c := netcom.NewClient(netcom.WithBaseURL(srv.URL))
+
+	d, err := DataframeFromURL(context.Background(), c, "/", 1, []DfOpt{WithMaxRows(2)}, WithInterpretedColumns())
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+	assert.True(t, d.Truncated())
+	assert.Equal(t, []Record{{"2024-01-01", "1"}, {"2024-01-02", "2"}}, d.Rows)
+
+	calls = 0
+	_, err = DataframeFromURL(context.Background(), c, "/", 0, nil)
+	var httpErr *netcom.HTTPError
+	require.ErrorAs(t, err, &httpErr)
+	assert.Equal(t, http.StatusServiceUnavailable, httpErr.StatusCode)
+}
@@ -0,0 +1,180 @@
+package datamanagement
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pbnjay/grate"
+)
+
+// RowStream yields the records of the first sheet of a file one at a time without collecting them in a Dataframe;
+// every record is decoded and cleaned as by WithRecordsFromFiles and the header row, if any, is yielded like any other record
+type RowStream struct {
+	rows rowSource
+	// d only carries the decoding and cleaning settings; no rows are ever added to it
+	d *Dataframe
+	// decode is d.decodeAll for text files and keepCells for spreadsheets
+	decode func([]string) ([]string, error)
+	// split applies the comma splitting of WithRecordsFromFiles to sources read through grate; csvRows already split the records
+	split bool
+	err   error
+}
+
+// rowSource reads the raw records of a file; read returns io.EOF once they are exhausted
+type rowSource interface {
+	read() ([]string, error)
+	Close() error
+}
+
+// csvRows reads a text file record by record, so only the current record is held in memory
+type csvRows struct {
+	f *os.File
+	r *csv.Reader
+}
+
+func (c *csvRows) read() ([]string, error) {
+	return c.r.Read()
+}
+
+func (c *csvRows) Close() error {
+	return c.f.Close()
+}
+
+// sheetRows reads a spreadsheet through grate, which loads the whole sheet when it is opened
+type sheetRows struct {
+	source grate.Source
+	data   grate.Collection
+}
+
+func (s *sheetRows) read() ([]string, error) {
+	if s.data.Next() {
+		return s.data.Strings(), nil
+	}
+	if err := s.data.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+func (s *sheetRows) Close() error {
+	return s.source.Close()
+}
+
+// OpenDataframeStream opens the file at path for reading with Next; the caller must Close the stream.
+// .csv files are read one record at a time, other files (txt, tsv, xls, xlsx) are read through grate like WithRecordsFromFiles does,
+// which loads the whole sheet.
+// opts configure how the records are decoded and cleaned, e.g. WithEncoding or WithTrimCutset; loading opts must not be passed
+func OpenDataframeStream(path string, opts ...DfOpt) (*RowStream, error) {
+	d := new(Dataframe)
+	for _, opt := range opts {
+		if err := opt(d); err != nil {
+			return nil, err
+		}
+	}
+	s := &RowStream{d: d, decode: d.decodeAll}
+	if !isTextFile(path) {
+		s.decode = keepCells
+	}
+	var err error
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		s.rows, err = openCSVRows(path)
+	} else {
+		s.rows, err = openSheetRows(path)
+		s.split = true
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func openCSVRows(path string) (*csvRows, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	r.ReuseRecord = true
+	return &csvRows{f: f, r: r}, nil
+}
+
+func openSheetRows(path string) (*sheetRows, error) {
+	source, err := grate.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	sheets, err := source.List()
+	if err != nil {
+		source.Close()
+		return nil, err
+	}
+	if len(sheets) == 0 {
+		source.Close()
+		return nil, fmt.Errorf("%w:%s", ErrNoSheets, path)
+	}
+	data, err := source.Get(sheets[0])
+	if err != nil {
+		source.Close()
+		return nil, err
+	}
+	return &sheetRows{source: source, data: data}, nil
+}
+
+// Next returns the next non-empty record; ok is false once the file is exhausted or reading failed, see Err
+func (s *RowStream) Next() (Record, bool) {
+	for s.err == nil {
+		raw, err := s.rows.read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			s.err = err
+			break
+		}
+		if len(raw) == 0 {
+			continue
+		}
+		r, err := s.decode(raw)
+		if err != nil {
+			s.err = err
+			break
+		}
+		var cr Record
+		if s.split && strings.Contains(r[0], ",") {
+			cr = s.d.cleanRecord(strings.Split(r[0], ","))
+		} else {
+			cr = s.d.cleanRecord(r)
+		}
+		if emptyRecord(cr) {
+			continue
+		}
+		return cr, true
+	}
+	return nil, false
+}
+
+// Err returns the error that stopped Next, if any
+func (s *RowStream) Err() error {
+	return s.err
+}
+
+// Close releases the underlying file
+func (s *RowStream) Close() error {
+	return s.rows.Close()
+}
+
+// Iterate calls fn with every row of d in order, stopping at the first error fn returns
+func (d *Dataframe) Iterate(fn func(Record) error) error {
+	for _, r := range d.Rows {
+		if err := fn(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
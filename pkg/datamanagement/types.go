@@ -0,0 +1,84 @@
+package datamanagement
+
+import (
+	"strconv"
+	"time"
+)
+
+type columnKind int
+
+const (
+	textColumn columnKind = iota
+	numericColumn
+	dateColumn
+)
+
+// dateLayouts are the layouts accepted when inferring date columns
+var dateLayouts = []string{time.DateOnly, time.RFC3339, time.DateTime}
+
+// inferColumnKinds classifies every column by its non-empty cells: numeric if all parse as numbers, date if all parse with one of dateLayouts;
+// columns without any non-empty cell are text
+func (d *Dataframe) inferColumnKinds() []columnKind {
+	kinds := make([]columnKind, len(d.Columns))
+	for cid, c := range d.Columns {
+		numeric, date, filled := true, true, false
+		for _, r := range d.Rows {
+			v := r[c.idx]
+			if len(v) == 0 {
+				continue
+			}
+			filled = true
+			if numeric {
+				_, err := strconv.ParseFloat(v, 64)
+				numeric = err == nil
+			}
+			if date {
+				_, date = parseDate(v, dateLayouts, false)
+			}
+			if !numeric && !date {
+				break
+			}
+		}
+		switch {
+		case !filled:
+			kinds[cid] = textColumn
+		case numeric:
+			kinds[cid] = numericColumn
+		case date:
+			kinds[cid] = dateColumn
+		}
+	}
+	return kinds
+}
+
+// TypedRecords returns every row as a map keyed by column name with values converted to the inferred column type:
+// float64 for numeric columns, time.Time for date columns and string otherwise; empty cells are nil so they map to SQL NULL
+func (d *Dataframe) TypedRecords() ([]map[string]any, error) {
+	kinds := d.inferColumnKinds()
+	records := make([]map[string]any, len(d.Rows))
+	for ridx, r := range d.Rows {
+		rec := make(map[string]any, len(d.Columns))
+		for cid, c := range d.Columns {
+			v := r[c.idx]
+			if len(v) == 0 {
+				rec[c.name] = nil
+				continue
+			}
+			switch kinds[cid] {
+			case numericColumn:
+				f, err := strconv.ParseFloat(v, 64)
+				if err != nil {
+					return nil, err
+				}
+				rec[c.name] = f
+			case dateColumn:
+				t, _ := parseDate(v, dateLayouts, false)
+				rec[c.name] = t
+			default:
+				rec[c.name] = v
+			}
+		}
+		records[ridx] = rec
+	}
+	return records, nil
+}
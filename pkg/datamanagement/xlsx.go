@@ -0,0 +1,56 @@
+package datamanagement
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// WriteXLSX writes the header and rows of the dataframe to a sheet named sheetName and streams the workbook to w;
+// columns inferred as numeric are written as numeric cells, everything else as text
+func (d *Dataframe) WriteXLSX(w io.Writer, sheetName string) error {
+	f := excelize.NewFile()
+	defer f.Close()
+	if err := f.SetSheetName(f.GetSheetName(0), sheetName); err != nil {
+		return err
+	}
+	sw, err := f.NewStreamWriter(sheetName)
+	if err != nil {
+		return err
+	}
+	kinds := d.inferColumnKinds()
+	header := make([]any, len(d.Columns))
+	for idx, h := range d.Header() {
+		header[idx] = h
+	}
+	if err := sw.SetRow("A1", header); err != nil {
+		return err
+	}
+	for ridx, r := range d.Rows {
+		cells := make([]any, len(d.Columns))
+		for cid, c := range d.Columns {
+			v := r[c.idx]
+			cells[cid] = v
+			if kinds[cid] == numericColumn && len(v) != 0 {
+				// already validated by inferColumnKinds
+				cells[cid], _ = strconv.ParseFloat(v, 64)
+			}
+		}
+		cell, err := excelize.CoordinatesToCellName(1, ridx+2)
+		if err != nil {
+			return err
+		}
+		if err := sw.SetRow(cell, cells); err != nil {
+			return err
+		}
+	}
+	if err := sw.Flush(); err != nil {
+		return err
+	}
+	if _, err := f.WriteTo(w); err != nil {
+		return fmt.Errorf("failed to write workbook:%w", err)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Statement is a single query with its arguments
+type Statement struct {
+	Query string
+	Args  []any
+}
+
+// BatchErr reports the batch that failed; batches before it are committed
+type BatchErr struct {
+	Committed int
+	Err       error
+}
+
+func (e *BatchErr) Error() string {
+	return fmt.Sprintf("batch %d failed after %d committed batches:%s", e.Committed+1, e.Committed, e.Err)
+}
+
+func (e *BatchErr) Unwrap() error {
+	return e.Err
+}
+
+// BatchExec runs stmts in transactions of batchSize statements each, committing after every batch;
+// a failure rolls back only the current batch and is returned as a *BatchErr
+func BatchExec(ctx context.Context, db *Database, stmts []Statement, batchSize int) error {
+	if batchSize < 1 {
+		return fmt.Errorf("%w:batch size must be positive", ErrBadConfig)
+	}
+	committed := 0
+	for start := 0; start < len(stmts); start += batchSize {
+		end := min(start+batchSize, len(stmts))
+		if err := execBatch(ctx, db, stmts[start:end]); err != nil {
+			return &BatchErr{Committed: committed, Err: err}
+		}
+		committed++
+	}
+	return nil
+}
+
+func execBatch(ctx context.Context, db *Database, stmts []Statement) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	for _, s := range stmts {
+		started := time.Now()
+		res, err := tx.ExecContext(ctx, s.Query, s.Args...)
+		db.traceExec(s.Query, s.Args, started, res, err)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
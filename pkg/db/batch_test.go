@@ -0,0 +1,75 @@
+package db
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchExec(t *testing.T) {
+	errBroken := errors.New("broken statement")
+	db := useFake(t)
+	fake.mu.Lock()
+	fake.exec = func(query string, _ []driver.NamedValue) error {
+		if query == "broken" {
+			return errBroken
+		}
+		return nil
+	}
+	fake.mu.Unlock()
+
+	stmts := []Statement{
+		{Query: "ok"}, {Query: "ok"},
+		{Query: "ok"}, {Query: "ok"},
+		{Query: "ok"}, {Query: "broken"},
+		{Query: "ok"},
+	}
+	err := BatchExec(context.Background(), db, stmts, 2)
+	var batchErr *BatchErr
+	require.ErrorAs(t, err, &batchErr)
+	assert.Equal(t, 2, batchErr.Committed)
+	assert.ErrorIs(t, err, errBroken)
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	assert.Equal(t, 2, fake.commits)
+	assert.Equal(t, 1, fake.rollbacks)
+}
+
+func TestBatchExecCommitsEveryBatch(t *testing.T) {
+	db := useFake(t)
+	stmts := make([]Statement, 5)
+	for i := range stmts {
+		stmts[i] = Statement{Query: "ok"}
+	}
+	require.NoError(t, BatchExec(context.Background(), db, stmts, 2))
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	assert.Equal(t, 3, fake.commits)
+	assert.Zero(t, fake.rollbacks)
+}
+
+func TestBatchExecBadSize(t *testing.T) {
+	db := useFake(t)
+	assert.ErrorIs(t, BatchExec(context.Background(), db, []Statement{{Query: "ok"}}, 0), ErrBadConfig)
+}
+
+func TestBatchExecTracesStatements(t *testing.T) {
+	var traces []QueryTrace
+	db := useFake(t, WithQueryLogger(func(qt QueryTrace) { traces = append(traces, qt) }))
+	stmts := []Statement{{Query: "first", Args: []any{1}}, {Query: "second"}, {Query: "third"}}
+	require.NoError(t, BatchExec(context.Background(), db, stmts, 2))
+
+	require.Len(t, traces, 3)
+	for i, qt := range traces {
+		assert.Equal(t, stmts[i].Query, qt.Query)
+		assert.Equal(t, len(stmts[i].Args), qt.Args)
+		assert.Equal(t, int64(1), qt.Rows)
+		assert.NoError(t, qt.Err)
+	}
+}
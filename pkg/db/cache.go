@@ -0,0 +1,67 @@
+package db
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ResultCache stores query results in process as they were scanned, so values without a lossless encoding (unexported fields,
+// time.Time locations) come back unchanged
+type ResultCache interface {
+	Get(key string) (any, bool)
+	Set(key string, value any, ttl time.Duration)
+}
+
+// QueryRowsCached returns a copy of the results cached under key if they are still fresh and of type []T;
+// otherwise it runs query, scans every row with scanner and caches the result for ttl
+func QueryRowsCached[T any](ctx context.Context, db *Database, cache ResultCache, key string, ttl time.Duration, query string, scanner Scanner[T], args ...any) ([]T, error) {
+	if cached, ok := cache.Get(key); ok {
+		if result, ok := cached.([]T); ok {
+			// the copy keeps callers appending to or editing the slice from changing the cached one
+			return slices.Clone(result), nil
+		}
+	}
+	result, err := QueryRows(ctx, db, query, scanner, args...)
+	if err != nil {
+		return nil, err
+	}
+	cache.Set(key, slices.Clone(result), ttl)
+	return result, nil
+}
+
+type memoryEntry struct {
+	value   any
+	expires time.Time
+}
+
+// MemoryCache is an in-process ResultCache; expired entries are dropped on access
+type MemoryCache struct {
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+}
+
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{entries: make(map[string]memoryEntry)}
+}
+
+func (mc *MemoryCache) Get(key string) (any, bool) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	e, ok := mc.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(e.expires) {
+		delete(mc.entries, key)
+		return nil, false
+	}
+	return e.value, true
+}
+
+func (mc *MemoryCache) Set(key string, value any, ttl time.Duration) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	mc.entries[key] = memoryEntry{value: value, expires: time.Now().Add(ttl)}
+}
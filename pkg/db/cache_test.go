@@ -0,0 +1,53 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// reading holds a value that does not survive a JSON round trip: an unexported field and a non UTC location
+type reading struct {
+	At    time.Time
+	plant string
+}
+
+func TestQueryRowsCached(t *testing.T) {
+	db := useFake(t)
+	queries := 0
+	sofia := time.FixedZone("EET", 2*60*60)
+	fake.query = func(string, []driver.NamedValue) ([]string, [][]driver.Value, error) {
+		queries++
+		return []string{"at", "plant"}, [][]driver.Value{{time.Date(2024, 1, 1, 8, 0, 0, 0, sofia), "sofia"}}, nil
+	}
+	scan := func(rows *sql.Rows) (reading, error) {
+		var r reading
+		err := rows.Scan(&r.At, &r.plant)
+		return r, err
+	}
+	cache := NewMemoryCache()
+	want := []reading{{At: time.Date(2024, 1, 1, 8, 0, 0, 0, sofia), plant: "sofia"}}
+
+	got, err := QueryRowsCached(context.Background(), db, cache, "readings", 50*time.Millisecond, "SELECT at, plant FROM readings", scan)
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+	assert.Equal(t, 1, queries)
+
+	// a hit returns the scanned values untouched and does not query
+	got[0].plant = "changed by the caller"
+	got, err = QueryRowsCached(context.Background(), db, cache, "readings", 50*time.Millisecond, "SELECT at, plant FROM readings", scan)
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+	assert.Equal(t, sofia, got[0].At.Location())
+	assert.Equal(t, 1, queries)
+
+	time.Sleep(60 * time.Millisecond)
+	_, err = QueryRowsCached(context.Background(), db, cache, "readings", 50*time.Millisecond, "SELECT at, plant FROM readings", scan)
+	require.NoError(t, err)
+	assert.Equal(t, 2, queries)
+}
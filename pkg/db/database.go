@@ -2,11 +2,14 @@ package db
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
 	"html/template"
 	"reflect"
+	"strings"
+	"time"
 )
 
 var ErrBadConfig = errors.New("the configuration provided is missing fields or has bad values in the provided fields")
@@ -47,6 +50,10 @@ type DatabaseConfig struct {
 	} `json:"credentials" yaml:"credentials"`
 	/* 	 ConnectionStringTemplate example:"sqlserver://{{.Credentials.Name}}:{{.Credentials.Password}}@{{.Address}}/?database={{.Name}}" */
 	ConnectionStringTemplate *template.Template
+	// ConnMaxIdleTime closes pooled connections idle for longer; keep it below the server's idle timeout. Zero keeps them forever
+	ConnMaxIdleTime time.Duration `json:"connMaxIdleTime" yaml:"connMaxIdleTime"`
+	// KeepaliveInterval pings a pooled connection at this interval so the server does not drop an idle pool; zero disables it
+	KeepaliveInterval time.Duration `json:"keepaliveInterval" yaml:"keepaliveInterval"`
 }
 
 type Database struct {
@@ -55,6 +62,26 @@ type Database struct {
 	connString string
 	prepStmts  map[string]*sql.Stmt
 	open       bool
+	// stopKeepalive ends the keepalive goroutine, which closes keepaliveDone once it has returned
+	stopKeepalive context.CancelFunc
+	keepaliveDone chan struct{}
+	queryLogger   func(QueryTrace)
+}
+
+// DatabaseOpt defines a function that modifies the database on construction
+type DatabaseOpt func(*Database)
+
+// RedactedDSN renders the connection string with the password replaced by ****; safe for logs and error messages
+func (c DatabaseConfig) RedactedDSN() string {
+	if c.ConnectionStringTemplate == nil {
+		return ""
+	}
+	c.Credentials.Password = "****"
+	dsn := bytes.NewBuffer([]byte{})
+	if err := c.ConnectionStringTemplate.Execute(dsn, c); err != nil {
+		return ""
+	}
+	return dsn.String()
 }
 
 func ValidateConfig(c DatabaseConfig) error {
@@ -65,29 +92,96 @@ func ValidateConfig(c DatabaseConfig) error {
 	return nil
 }
 
-func NewDatabase(c DatabaseConfig, name string) (*Database, error) {
+func NewDatabase(c DatabaseConfig, name string, opts ...DatabaseOpt) (*Database, error) {
 	if err := ValidateConfig(c); err != nil {
 		return nil, err
 	}
 	connectionString := bytes.NewBuffer([]byte{})
 	db := new(Database)
 	db.Config = c
+	for _, opt := range opts {
+		opt(db)
+	}
 	err := db.Config.ConnectionStringTemplate.Execute(connectionString, db.Config)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("connection string rendering failed:%w", err)
+	}
+	if empty := c.emptyTemplateFields(); len(empty) > 0 {
+		return nil, fmt.Errorf("%w:the connection string template references empty fields %s", ErrBadConfig, strings.Join(empty, ", "))
 	}
 	db.connString = connectionString.String()
 
 	db.DB, err = sql.Open(db.Config.Driver, db.connString)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to open %s:%w", c.RedactedDSN(), err)
 	}
+	db.SetConnMaxIdleTime(c.ConnMaxIdleTime)
 	db.open = true
 	db.prepStmts = make(map[string]*sql.Stmt)
+	if c.KeepaliveInterval > 0 {
+		ctx, stop := context.WithCancel(context.Background())
+		db.stopKeepalive, db.keepaliveDone = stop, make(chan struct{})
+		go db.keepalive(ctx, c.KeepaliveInterval, db.keepaliveDone)
+	}
+	return db, nil
+}
+
+// keepalive pings a pooled connection each interval until ctx is canceled and then closes done
+func (pdb *Database) keepalive(ctx context.Context, interval time.Duration, done chan<- struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			pdb.ping(ctx, interval)
+		}
+	}
+}
+
+// ping checks out a single pooled connection, preferring an idle one, so that the traffic of a busy pool is never held up
+func (pdb *Database) ping(ctx context.Context, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	// a failed ping marks the connection bad and the pool discards it
+	pdb.PingContext(ctx)
+}
+
+// NewDatabaseFromDSN opens an already rendered connection string and pings it; no template or credentials are required
+func NewDatabaseFromDSN(driver, dsn string, opts ...DatabaseOpt) (*Database, error) {
+	if len(driver) == 0 || len(dsn) == 0 {
+		return nil, ErrBadConfig
+	}
+	sqlDB, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s database:%w", driver, err)
+	}
+	if err := sqlDB.Ping(); err != nil {
+		sqlDB.Close()
+		return nil, fmt.Errorf("failed to reach %s database:%w", driver, err)
+	}
+	db := &Database{
+		DB:         sqlDB,
+		Config:     DatabaseConfig{Driver: driver},
+		connString: dsn,
+		prepStmts:  make(map[string]*sql.Stmt),
+		open:       true,
+	}
+	for _, opt := range opts {
+		opt(db)
+	}
 	return db, nil
 }
 
 func (pdb *Database) Close() error {
+	if pdb.stopKeepalive != nil {
+		pdb.stopKeepalive()
+		// the goroutine may be holding pooled connections; they must be released before the pool is closed
+		<-pdb.keepaliveDone
+		pdb.stopKeepalive = nil
+	}
 	err := pdb.DB.Close()
 	if err != nil {
 		return err
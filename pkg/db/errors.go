@@ -0,0 +1,116 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// Driver independent error classes returned by NormalizeError; the driver error stays in the chain
+var (
+	ErrUniqueViolation = errors.New("unique constraint violation")
+	ErrForeignKey      = errors.New("foreign key violation")
+	ErrDeadlock        = errors.New("deadlock detected")
+	ErrTimeout         = errors.New("statement timed out")
+)
+
+// ErrorNormalizer maps a driver error onto one of the common error classes; it returns nil if err is not one of them
+type ErrorNormalizer func(err error) error
+
+var (
+	normalizersMu sync.RWMutex
+	normalizers   = map[string]ErrorNormalizer{
+		"mysql":     normalizeMySQL,
+		"postgres":  normalizePostgres,
+		"pgx":       normalizePostgres,
+		"sqlserver": normalizeMSSQL,
+		"mssql":     normalizeMSSQL,
+		"azuresql":  normalizeMSSQL,
+	}
+)
+
+// RegisterErrorNormalizer sets the normalizer used for driver, replacing the built-in one if present
+func RegisterErrorNormalizer(driver string, fn ErrorNormalizer) {
+	normalizersMu.Lock()
+	defer normalizersMu.Unlock()
+	normalizers[driver] = fn
+}
+
+// NormalizeError wraps err with ErrUniqueViolation, ErrForeignKey, ErrDeadlock or ErrTimeout when the driver reports one of them,
+// so callers can use errors.Is regardless of the driver; other errors are returned unchanged
+func NormalizeError(driver string, err error) error {
+	if err == nil {
+		return nil
+	}
+	normalizersMu.RLock()
+	fn, ok := normalizers[driver]
+	normalizersMu.RUnlock()
+	if ok {
+		if class := fn(err); class != nil {
+			return fmt.Errorf("%w: %w", class, err)
+		}
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %w", ErrTimeout, err)
+	}
+	return err
+}
+
+func normalizeMySQL(err error) error {
+	var myErr *mysql.MySQLError
+	if !errors.As(err, &myErr) {
+		return nil
+	}
+	switch myErr.Number {
+	case 1062:
+		return ErrUniqueViolation
+	case 1451, 1452:
+		return ErrForeignKey
+	case 1213:
+		return ErrDeadlock
+	case 1205, 3024:
+		return ErrTimeout
+	}
+	return nil
+}
+
+// normalizePostgres relies on the SQLState method implemented by both lib/pq and pgx errors
+func normalizePostgres(err error) error {
+	var pgErr interface{ SQLState() string }
+	if !errors.As(err, &pgErr) {
+		return nil
+	}
+	switch pgErr.SQLState() {
+	case "23505":
+		return ErrUniqueViolation
+	case "23503":
+		return ErrForeignKey
+	case "40P01":
+		return ErrDeadlock
+	case "57014", "55P03":
+		return ErrTimeout
+	}
+	return nil
+}
+
+// normalizeMSSQL relies on the SQLErrorNumber method of go-mssqldb errors
+func normalizeMSSQL(err error) error {
+	var msErr interface{ SQLErrorNumber() int32 }
+	if !errors.As(err, &msErr) {
+		return nil
+	}
+	switch msErr.SQLErrorNumber() {
+	case 2601, 2627:
+		return ErrUniqueViolation
+	case 547:
+		return ErrForeignKey
+	case 1205:
+		return ErrDeadlock
+	case 1222:
+		return ErrTimeout
+	}
+	return nil
+}
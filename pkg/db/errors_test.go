@@ -0,0 +1,72 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+)
+
+// sqlStateErr mimics the errors of lib/pq and pgx
+type sqlStateErr string
+
+func (e sqlStateErr) Error() string    { return "pq: " + string(e) }
+func (e sqlStateErr) SQLState() string { return string(e) }
+
+// sqlErrorNumberErr mimics the errors of go-mssqldb
+type sqlErrorNumberErr int32
+
+func (e sqlErrorNumberErr) Error() string         { return fmt.Sprintf("mssql: %d", int32(e)) }
+func (e sqlErrorNumberErr) SQLErrorNumber() int32 { return int32(e) }
+
+func TestNormalizeError(t *testing.T) {
+	other := errors.New("syntax error")
+	tests := []struct {
+		name   string
+		driver string
+		err    error
+		want   error
+	}{
+		{"mysql duplicate", "mysql", &mysql.MySQLError{Number: 1062}, ErrUniqueViolation},
+		{"mysql foreign key", "mysql", &mysql.MySQLError{Number: 1452}, ErrForeignKey},
+		{"mysql deadlock", "mysql", &mysql.MySQLError{Number: 1213}, ErrDeadlock},
+		{"mysql lock wait", "mysql", &mysql.MySQLError{Number: 1205}, ErrTimeout},
+		{"postgres unique", "postgres", sqlStateErr("23505"), ErrUniqueViolation},
+		{"pgx foreign key", "pgx", fmt.Errorf("insert failed:%w", sqlStateErr("23503")), ErrForeignKey},
+		{"postgres deadlock", "postgres", sqlStateErr("40P01"), ErrDeadlock},
+		{"postgres canceled", "postgres", sqlStateErr("57014"), ErrTimeout},
+		{"sqlserver unique", "sqlserver", sqlErrorNumberErr(2627), ErrUniqueViolation},
+		{"mssql foreign key", "mssql", sqlErrorNumberErr(547), ErrForeignKey},
+		{"azuresql deadlock", "azuresql", sqlErrorNumberErr(1205), ErrDeadlock},
+		{"sqlserver lock timeout", "sqlserver", sqlErrorNumberErr(1222), ErrTimeout},
+		{"context deadline", "sqlite3", context.DeadlineExceeded, ErrTimeout},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := NormalizeError(tc.driver, tc.err)
+			assert.ErrorIs(t, got, tc.want)
+			// the driver error stays reachable
+			assert.ErrorIs(t, got, tc.err)
+		})
+	}
+
+	assert.NoError(t, NormalizeError("mysql", nil))
+	assert.Same(t, other, NormalizeError("mysql", other))
+	assert.Equal(t, error(sqlStateErr("42601")), NormalizeError("postgres", sqlStateErr("42601")))
+	// a driver's code means nothing to another driver's normalizer
+	assert.NotErrorIs(t, NormalizeError("sqlserver", sqlStateErr("23505")), ErrUniqueViolation)
+}
+
+func TestRegisterErrorNormalizer(t *testing.T) {
+	custom := errors.New("custom duplicate")
+	RegisterErrorNormalizer("custom", func(err error) error {
+		if errors.Is(err, custom) {
+			return ErrUniqueViolation
+		}
+		return nil
+	})
+	assert.ErrorIs(t, NormalizeError("custom", custom), ErrUniqueViolation)
+}
@@ -0,0 +1,18 @@
+package db
+
+import "strings"
+
+// QuoteIdent quotes a table or column name for use in dynamically built SQL according to the conventions of driver;
+// quote characters embedded in ident are escaped by doubling them. Unknown drivers fall back to ANSI double quotes.
+// The identifier is quoted as a whole - qualified names (schema.table) must be quoted part by part.
+func QuoteIdent(driver, ident string) string {
+	switch driver {
+	case "mysql":
+		return "`" + strings.ReplaceAll(ident, "`", "``") + "`"
+	case "sqlserver", "mssql", "azuresql":
+		return "[" + strings.ReplaceAll(ident, "]", "]]") + "]"
+	default:
+		// postgres, sqlite3 and the SQL standard
+		return `"` + strings.ReplaceAll(ident, `"`, `""`) + `"`
+	}
+}
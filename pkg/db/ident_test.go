@@ -0,0 +1,35 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuoteIdent(t *testing.T) {
+	tests := []struct {
+		driver, ident, want string
+	}{
+		{"mysql", "orders", "`orders`"},
+		{"mysql", "a`b", "`a``b`"},
+		{"mysql", "`; DROP TABLE orders; --", "```; DROP TABLE orders; --`"},
+		{"mysql", `a"b[c]`, "`a\"b[c]`"},
+		{"mysql", "sales.orders", "`sales.orders`"},
+		{"sqlserver", "orders", "[orders]"},
+		{"sqlserver", "a]b", "[a]]b]"},
+		{"mssql", "]; DROP TABLE orders; --", "[]]; DROP TABLE orders; --]"},
+		{"azuresql", "a[b`c\"", "[a[b`c\"]"},
+		{"sqlserver", "sales.orders", "[sales.orders]"},
+		{"postgres", "orders", `"orders"`},
+		{"postgres", `a"b`, `"a""b"`},
+		{"pgx", `"; DROP TABLE orders; --`, `"""; DROP TABLE orders; --"`},
+		{"sqlite3", "a`b[c]", "\"a`b[c]\""},
+		{"postgres", "sales.orders", `"sales.orders"`},
+		{"unknown", `a"b`, `"a""b"`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.driver+"/"+tc.ident, func(t *testing.T) {
+			assert.Equal(t, tc.want, QuoteIdent(tc.driver, tc.ident))
+		})
+	}
+}
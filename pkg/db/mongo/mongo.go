@@ -0,0 +1,79 @@
+// Package mongo is a thin MongoDB adapter driven by the same db.DatabaseConfig as the SQL sources;
+// it lives in its own package so that importing pkg/db does not pull in the Mongo driver
+package mongo
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ivanehh/boiler/pkg/db"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+const Driver = "mongodb"
+
+var ErrNoDatabase = errors.New("no database name was provided in the configuration")
+
+type Database struct {
+	Config db.DatabaseConfig
+	client *mongo.Client
+}
+
+// NewDatabase connects to the MongoDB instance described by c; Address and Name are required.
+// If a ConnectionStringTemplate is provided it is rendered as the connection URI, otherwise the URI is built from Address;
+// non-empty Credentials are always applied
+func NewDatabase(ctx context.Context, c db.DatabaseConfig) (*Database, error) {
+	if len(c.Address) == 0 {
+		return nil, db.ErrBadConfig
+	}
+	if len(c.Name) == 0 {
+		return nil, ErrNoDatabase
+	}
+	uri := "mongodb://" + c.Address
+	if c.ConnectionStringTemplate != nil {
+		rendered := bytes.NewBuffer([]byte{})
+		if err := c.ConnectionStringTemplate.Execute(rendered, c); err != nil {
+			return nil, fmt.Errorf("connection string rendering failed:%w", err)
+		}
+		uri = rendered.String()
+	}
+	opts := options.Client().ApplyURI(uri)
+	if len(c.Credentials.Name) != 0 {
+		opts.SetAuth(options.Credential{
+			Username: c.Credentials.Name,
+			Password: c.Credentials.Password,
+		})
+	}
+	client, err := mongo.Connect(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s:%w", c.Address, err)
+	}
+	return &Database{Config: c, client: client}, nil
+}
+
+// Ping reports whether the server is reachable; it is the Mongo counterpart of a health query
+func (mdb *Database) Ping(ctx context.Context) error {
+	return mdb.client.Ping(ctx, readpref.Primary())
+}
+
+// Find runs filter against collection and decodes all matching documents into results, which must be a pointer to a slice;
+// a nil filter matches every document
+func (mdb *Database) Find(ctx context.Context, collection string, filter any, results any) error {
+	if filter == nil {
+		filter = bson.D{}
+	}
+	cur, err := mdb.client.Database(mdb.Config.Name).Collection(collection).Find(ctx, filter)
+	if err != nil {
+		return err
+	}
+	return cur.All(ctx, results)
+}
+
+func (mdb *Database) Close(ctx context.Context) error {
+	return mdb.client.Disconnect(ctx)
+}
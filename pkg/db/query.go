@@ -0,0 +1,82 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// ErrNoRows is returned by QueryRowsOrError when the query matched nothing
+var ErrNoRows = sql.ErrNoRows
+
+// Scanner converts the current row of rows into a T
+type Scanner[T any] func(rows *sql.Rows) (T, error)
+
+// QueryRows runs query and scans every row with scanner; a query without matches yields a non-nil empty slice
+func QueryRows[T any](ctx context.Context, db *Database, query string, scanner Scanner[T], args ...any) ([]T, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	result := make([]T, 0)
+	for rows.Next() {
+		item, err := scanner(rows)
+		if err != nil {
+			return nil, fmt.Errorf("row scan failed:%w", err)
+		}
+		result = append(result, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// QueryRowsOrError is QueryRows for callers that need at least one row; it returns ErrNoRows when nothing matched
+func QueryRowsOrError[T any](ctx context.Context, db *Database, query string, scanner Scanner[T], args ...any) ([]T, error) {
+	result, err := QueryRows(ctx, db, query, scanner, args...)
+	if err != nil {
+		return nil, err
+	}
+	if len(result) == 0 {
+		return nil, ErrNoRows
+	}
+	return result, nil
+}
+
+// ScanInt scans a single column row into an int64; NULL yields 0
+func ScanInt(rows *sql.Rows) (int64, error) {
+	var v sql.NullInt64
+	err := rows.Scan(&v)
+	return v.Int64, err
+}
+
+// ScanString scans a single column row into a string; NULL yields ""
+func ScanString(rows *sql.Rows) (string, error) {
+	var v sql.NullString
+	err := rows.Scan(&v)
+	return v.String, err
+}
+
+// ScanFloat scans a single column row into a float64; NULL yields 0
+func ScanFloat(rows *sql.Rows) (float64, error) {
+	var v sql.NullFloat64
+	err := rows.Scan(&v)
+	return v.Float64, err
+}
+
+// ScanTime scans a single column row into a time.Time; NULL yields the zero time
+func ScanTime(rows *sql.Rows) (time.Time, error) {
+	var v sql.NullTime
+	err := rows.Scan(&v)
+	return v.Time, err
+}
+
+// ScanBool scans a single column row into a bool; NULL yields false
+func ScanBool(rows *sql.Rows) (bool, error) {
+	var v sql.NullBool
+	err := rows.Scan(&v)
+	return v.Bool, err
+}
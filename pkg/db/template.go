@@ -0,0 +1,63 @@
+package db
+
+import (
+	"reflect"
+	"strings"
+	"text/template/parse"
+)
+
+// emptyTemplateFields returns the fields referenced by the connection string template (e.g. .Credentials.Name) that are empty in c
+func (c DatabaseConfig) emptyTemplateFields() []string {
+	if c.ConnectionStringTemplate == nil || c.ConnectionStringTemplate.Tree == nil {
+		return nil
+	}
+	var fields [][]string
+	collectFields(c.ConnectionStringTemplate.Tree.Root, &fields)
+	empty := make([]string, 0)
+	cv := reflect.ValueOf(c)
+	for _, path := range fields {
+		v := cv
+		for _, name := range path {
+			if v.Kind() != reflect.Struct {
+				v = reflect.Value{}
+				break
+			}
+			v = v.FieldByName(name)
+		}
+		if v.IsValid() && v.IsZero() {
+			empty = append(empty, "."+strings.Join(path, "."))
+		}
+	}
+	return empty
+}
+
+func collectFields(node parse.Node, fields *[][]string) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			collectFields(child, fields)
+		}
+	case *parse.ActionNode:
+		collectFields(n.Pipe, fields)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			for _, arg := range cmd.Args {
+				collectFields(arg, fields)
+			}
+		}
+	case *parse.FieldNode:
+		*fields = append(*fields, n.Ident)
+	case *parse.IfNode:
+		collectFields(n.List, fields)
+		collectFields(n.ElseList, fields)
+	case *parse.WithNode:
+		collectFields(n.List, fields)
+		collectFields(n.ElseList, fields)
+	}
+}
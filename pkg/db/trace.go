@@ -0,0 +1,56 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// QueryTrace describes a single statement run through Database.QueryContext, Database.ExecContext or BatchExec;
+// Rows holds the affected rows of an Exec and is -1 for queries since their rows are read by the caller
+type QueryTrace struct {
+	Query    string
+	Args     int
+	Duration time.Duration
+	Rows     int64
+	Err      error
+}
+
+// WithQueryLogger calls fn after every QueryContext and ExecContext and every statement run by BatchExec
+func WithQueryLogger(fn func(QueryTrace)) DatabaseOpt {
+	return func(db *Database) {
+		db.queryLogger = fn
+	}
+}
+
+// QueryContext wraps sql.DB.QueryContext and reports the call to the query logger
+func (pdb *Database) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	started := time.Now()
+	rows, err := pdb.DB.QueryContext(ctx, query, args...)
+	if pdb.queryLogger != nil {
+		pdb.queryLogger(QueryTrace{Query: query, Args: len(args), Duration: time.Since(started), Rows: -1, Err: err})
+	}
+	return rows, err
+}
+
+// ExecContext wraps sql.DB.ExecContext and reports the call to the query logger
+func (pdb *Database) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	started := time.Now()
+	res, err := pdb.DB.ExecContext(ctx, query, args...)
+	pdb.traceExec(query, args, started, res, err)
+	return res, err
+}
+
+// traceExec reports an Exec started at started to the query logger, including those run in a transaction by BatchExec
+func (pdb *Database) traceExec(query string, args []any, started time.Time, res sql.Result, err error) {
+	if pdb.queryLogger == nil {
+		return
+	}
+	trace := QueryTrace{Query: query, Args: len(args), Duration: time.Since(started), Rows: -1, Err: err}
+	if err == nil {
+		if affected, rerr := res.RowsAffected(); rerr == nil {
+			trace.Rows = affected
+		}
+	}
+	pdb.queryLogger(trace)
+}
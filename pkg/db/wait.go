@@ -0,0 +1,35 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ivanehh/boiler/pkg/logging"
+)
+
+// WaitForDB opens the database described by cfg and pings it every interval until it answers or ctx is done;
+// an invalid configuration is returned right away and failed attempts are logged as warnings to logger, which may be nil
+func WaitForDB(ctx context.Context, cfg DatabaseConfig, interval time.Duration, logger *logging.Logger, opts ...DatabaseOpt) (*Database, error) {
+	for attempt := 1; ; attempt++ {
+		db, err := NewDatabase(cfg, cfg.Name, opts...)
+		if errors.Is(err, ErrBadConfig) {
+			// retrying cannot fix the configuration
+			return nil, err
+		}
+		if err == nil {
+			if err = db.PingContext(ctx); err == nil {
+				return db, nil
+			}
+			db.Close()
+		}
+		if logger != nil {
+			logger.Warn("database not available yet", "address", cfg.Address, "attempt", attempt, "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
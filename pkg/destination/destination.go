@@ -0,0 +1,136 @@
+// Package destination writes pipeline results to the destinations declared in the configuration
+package destination
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ivanehh/boiler"
+	"github.com/ivanehh/boiler/pkg/ftp"
+	"github.com/ivanehh/boiler/pkg/netcom"
+)
+
+// Destination types recognized by NewDestination
+const (
+	HTTP  = "http"
+	HTTPS = "https"
+	FTP   = "ftp"
+	FS    = "fs"
+)
+
+var (
+	ErrUnknownType = errors.New("unknown destination type")
+	ErrDisabled    = errors.New("destination is disabled")
+)
+
+// Destination receives the data produced by a pipeline
+type Destination interface {
+	Write(ctx context.Context, data []byte) error
+}
+
+// DestinationOpt defines a function that modifies how destinations are constructed
+type DestinationOpt func(*options)
+
+type options struct {
+	disabledAsError bool
+	client          *netcom.Client
+}
+
+// WithDisabledAsError makes NewDestination return ErrDisabled for disabled destinations instead of a writer that discards the data
+func WithDisabledAsError() DestinationOpt {
+	return func(o *options) {
+		o.disabledAsError = true
+	}
+}
+
+// WithClient sets the netcom client used by HTTP destinations
+func WithClient(c *netcom.Client) DestinationOpt {
+	return func(o *options) {
+		o.client = c
+	}
+}
+
+// NewDestination builds the writer matching cfg.Type(): http(s) POSTs the data to the address, ftp uploads it and fs writes it to a file;
+// ftp and fs treat the address as a directory and name every write <name>_<UTC timestamp>
+func NewDestination(cfg boiler.IOWithAuth, opts ...DestinationOpt) (Destination, error) {
+	o := new(options)
+	for _, opt := range opts {
+		opt(o)
+	}
+	if !cfg.Enabled() {
+		if o.disabledAsError {
+			return nil, fmt.Errorf("%w:%s", ErrDisabled, cfg.Name())
+		}
+		return discard{}, nil
+	}
+	switch cfg.Type() {
+	case HTTP, HTTPS:
+		client := o.client
+		if client == nil {
+			client = netcom.NewClient()
+		}
+		return &httpDestination{cfg: cfg, client: client}, nil
+	case FTP:
+		return &ftpDestination{cfg: cfg}, nil
+	case FS:
+		return &fsDestination{cfg: cfg}, nil
+	default:
+		return nil, fmt.Errorf("%w:%s", ErrUnknownType, cfg.Type())
+	}
+}
+
+// fileName names the output of a single write
+func fileName(cfg boiler.IOWithAuth) string {
+	return fmt.Sprintf("%s_%s", cfg.Name(), time.Now().UTC().Format("20060102T150405.000000000"))
+}
+
+type discard struct{}
+
+func (discard) Write(context.Context, []byte) error {
+	return nil
+}
+
+type httpDestination struct {
+	cfg    boiler.IOWithAuth
+	client *netcom.Client
+}
+
+func (d *httpDestination) Write(ctx context.Context, data []byte) error {
+	options := []netcom.RequestOption{netcom.WithHeader("Content-Type", "application/octet-stream")}
+	if creds := d.cfg.Auth(); creds != nil && len(creds.Username()) != 0 {
+		options = append(options, func(req *http.Request) error {
+			req.SetBasicAuth(creds.Username(), creds.Password())
+			return nil
+		})
+	}
+	resp, err := d.client.Post(ctx, d.cfg.Addr(), bytes.NewReader(data), options...)
+	if err != nil {
+		return err
+	}
+	return netcom.DecodeResponse(resp, nil)
+}
+
+type ftpDestination struct {
+	cfg boiler.IOWithAuth
+}
+
+func (d *ftpDestination) Write(ctx context.Context, data []byte) error {
+	return ftp.Upload(ctx, d.cfg, fileName(d.cfg), bytes.NewReader(data))
+}
+
+type fsDestination struct {
+	cfg boiler.IOWithAuth
+}
+
+func (d *fsDestination) Write(_ context.Context, data []byte) error {
+	if err := os.MkdirAll(d.cfg.Addr(), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(d.cfg.Addr(), fileName(d.cfg)), data, 0o644)
+}
@@ -0,0 +1,130 @@
+// Package ftp connects to the FTP sources and destinations declared in the configuration
+package ftp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/ivanehh/boiler"
+	"github.com/jlaffaye/ftp"
+)
+
+const defaultPort = "21"
+
+// Dial connects and logs in to the server at endpoint.Addr(); the address has the form [ftp://]host[:port][/dir].
+// Connections without credentials log in as anonymous. The returned directory is the path part of the address
+func Dial(ctx context.Context, endpoint boiler.IOWithAuth) (*ftp.ServerConn, string, error) {
+	addr := endpoint.Addr()
+	if !strings.Contains(addr, "://") {
+		addr = "ftp://" + addr
+	}
+	u, err := url.Parse(addr)
+	if err != nil {
+		return nil, "", fmt.Errorf("bad ftp address %s:%w", endpoint.Addr(), err)
+	}
+	host := u.Host
+	if len(u.Port()) == 0 {
+		host += ":" + defaultPort
+	}
+	conn, err := ftp.Dial(host, ftp.DialWithContext(ctx))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to connect to %s:%w", host, err)
+	}
+	user, password := "anonymous", "anonymous"
+	if creds := endpoint.Auth(); creds != nil && len(creds.Username()) != 0 {
+		user, password = creds.Username(), creds.Password()
+	}
+	if err := conn.Login(user, password); err != nil {
+		conn.Quit()
+		return nil, "", fmt.Errorf("failed to log in to %s as %s:%w", host, user, err)
+	}
+	dir := u.Path
+	if len(dir) == 0 {
+		dir = "/"
+	}
+	return conn, dir, nil
+}
+
+// Upload stores the content of r as name in the directory of dst
+func Upload(ctx context.Context, dst boiler.IOWithAuth, name string, r io.Reader) error {
+	conn, dir, err := Dial(ctx, dst)
+	if err != nil {
+		return err
+	}
+	defer conn.Quit()
+	if err := conn.Stor(path.Join(dir, name), r); err != nil {
+		return fmt.Errorf("failed to upload %s:%w", name, err)
+	}
+	return nil
+}
+
+// Fetch downloads the regular files in the directory of src whose names match the glob pattern (see path.Match) into a new
+// temporary directory and returns their local paths; the caller owns the directory and should remove it when done
+func Fetch(ctx context.Context, src boiler.IOWithAuth, pattern string) ([]string, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("bad pattern %s:%w", pattern, err)
+	}
+	conn, dir, err := Dial(ctx, src)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Quit()
+	entries, err := conn.List(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s:%w", dir, err)
+	}
+	tmp, err := os.MkdirTemp("", "boiler-ftp-")
+	if err != nil {
+		return nil, err
+	}
+	paths, err := fetchMatching(ctx, conn, dir, entries, pattern, tmp)
+	if err != nil {
+		os.RemoveAll(tmp)
+		return nil, err
+	}
+	return paths, nil
+}
+
+func fetchMatching(ctx context.Context, conn *ftp.ServerConn, dir string, entries []*ftp.Entry, pattern, tmp string) ([]string, error) {
+	paths := make([]string, 0)
+	for _, e := range entries {
+		if e.Type != ftp.EntryTypeFile {
+			continue
+		}
+		if ok, _ := path.Match(pattern, e.Name); !ok {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		local := filepath.Join(tmp, e.Name)
+		if err := download(conn, path.Join(dir, e.Name), local); err != nil {
+			return nil, err
+		}
+		paths = append(paths, local)
+	}
+	return paths, nil
+}
+
+func download(conn *ftp.ServerConn, remote, local string) error {
+	resp, err := conn.Retr(remote)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve %s:%w", remote, err)
+	}
+	defer resp.Close()
+	f, err := os.Create(local)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, resp); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to download %s:%w", remote, err)
+	}
+	return f.Close()
+}
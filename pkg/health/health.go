@@ -0,0 +1,111 @@
+// Package health combines database, HTTP source and filesystem probes into a single JSON serializable report
+package health
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+
+	"github.com/ivanehh/boiler/pkg/db"
+	"github.com/ivanehh/boiler/pkg/netcom"
+)
+
+// Status is the outcome of probing a single component
+type Status struct {
+	Component string `json:"component"`
+	Healthy   bool   `json:"healthy"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Report is healthy only if every component is
+type Report struct {
+	Healthy    bool     `json:"healthy"`
+	Components []Status `json:"components"`
+}
+
+type probe struct {
+	component string
+	run       func(ctx context.Context) error
+}
+
+// CheckerOption defines a function that adds a probe to the checker
+type CheckerOption func(*Checker)
+
+// Checker runs the registered probes concurrently
+type Checker struct {
+	probes []probe
+}
+
+// NewChecker creates a new Checker with the given probes
+func NewChecker(options ...CheckerOption) *Checker {
+	c := new(Checker)
+	for _, option := range options {
+		option(c)
+	}
+	return c
+}
+
+// WithDatabase probes the database by pinging it
+func WithDatabase(name string, database *db.Database) CheckerOption {
+	return func(c *Checker) {
+		c.probes = append(c.probes, probe{component: name, run: func(ctx context.Context) error {
+			return database.PingContext(ctx)
+		}})
+	}
+}
+
+// WithHTTPSource probes an HTTP source with a GET to path; any response below 500 counts as reachable
+func WithHTTPSource(name string, client *netcom.Client, path string) CheckerOption {
+	return func(c *Checker) {
+		c.probes = append(c.probes, probe{component: name, run: func(ctx context.Context) error {
+			resp, err := client.Get(ctx, path)
+			if err != nil {
+				return err
+			}
+			resp.Body.Close()
+			if resp.StatusCode >= http.StatusInternalServerError {
+				return fmt.Errorf("unhealthy status %d", resp.StatusCode)
+			}
+			return nil
+		}})
+	}
+}
+
+// WithWritableDir probes that a file can be created in dir
+func WithWritableDir(name string, dir string) CheckerOption {
+	return func(c *Checker) {
+		c.probes = append(c.probes, probe{component: name, run: func(_ context.Context) error {
+			f, err := os.CreateTemp(dir, ".healthcheck-*")
+			if err != nil {
+				return err
+			}
+			f.Close()
+			return os.Remove(f.Name())
+		}})
+	}
+}
+
+// Check runs every probe and reports the status of each component in the order they were registered
+func (c *Checker) Check(ctx context.Context) Report {
+	report := Report{Healthy: true, Components: make([]Status, len(c.probes))}
+	var wg sync.WaitGroup
+	for idx, p := range c.probes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			status := Status{Component: p.component, Healthy: true}
+			if err := p.run(ctx); err != nil {
+				status.Healthy = false
+				status.Error = err.Error()
+			}
+			report.Components[idx] = status
+		}()
+	}
+	wg.Wait()
+	for _, s := range report.Components {
+		report.Healthy = report.Healthy && s.Healthy
+	}
+	return report
+}
@@ -13,28 +13,42 @@ import (
 type LoggerLevel string
 
 const (
+	TraceLevel LoggerLevel = "trace"
 	DebugLevel LoggerLevel = "debug"
 	InfoLevel  LoggerLevel = "info"
 	WarnLevel  LoggerLevel = "warn"
 	ErrorLevel LoggerLevel = "error"
+	FatalLevel LoggerLevel = "fatal"
+)
+
+// Custom slog levels outside of the four built into slog
+const (
+	LevelTrace slog.Level = -8
+	LevelFatal slog.Level = 12
 )
 
 // LoggerConfig holds configuration for the logger
 type LoggerConfig struct {
 	Level      LoggerLevel
 	JSONFormat bool
+	// OTelFormat emits records following the OpenTelemetry log data model; takes precedence over JSONFormat
+	OTelFormat bool
 	Output     io.Writer
 	TimeFormat string
 	AddSource  bool
 
 	// Additional outputs with format specification
 	AdditionalOutputs []OutputConfig
+
+	// Sampling thins out repeated messages on every output
+	Sampling SamplingConfig
 }
 
 // OutputConfig specifies an output destination with its format
 type OutputConfig struct {
 	Writer     io.Writer
 	JSONFormat bool
+	OTelFormat bool
 }
 
 // DefaultConfig returns the default logger configuration
@@ -60,8 +74,9 @@ type Logger struct {
 func New(config LoggerConfig) *Logger {
 	level := getLevelFromString(config.Level)
 	opts := &slog.HandlerOptions{
-		Level:     level,
-		AddSource: config.AddSource,
+		Level:       level,
+		AddSource:   config.AddSource,
+		ReplaceAttr: replaceLevel,
 	}
 
 	// Create handlers for each output
@@ -69,21 +84,13 @@ func New(config LoggerConfig) *Logger {
 
 	// Main output
 	if config.Output != nil {
-		if config.JSONFormat {
-			handlers = append(handlers, slog.NewJSONHandler(config.Output, opts))
-		} else {
-			handlers = append(handlers, slog.NewTextHandler(config.Output, opts))
-		}
+		handlers = append(handlers, newOutputHandler(config.Output, config.JSONFormat, config.OTelFormat, opts))
 	}
 
 	// Additional outputs
 	for _, outputConfig := range config.AdditionalOutputs {
 		if outputConfig.Writer != nil {
-			if outputConfig.JSONFormat {
-				handlers = append(handlers, slog.NewJSONHandler(outputConfig.Writer, opts))
-			} else {
-				handlers = append(handlers, slog.NewTextHandler(outputConfig.Writer, opts))
-			}
+			handlers = append(handlers, newOutputHandler(outputConfig.Writer, outputConfig.JSONFormat, outputConfig.OTelFormat, opts))
 		}
 	}
 
@@ -97,6 +104,9 @@ func New(config LoggerConfig) *Logger {
 		// Fallback to stdout with text format if no outputs specified
 		handler = slog.NewTextHandler(os.Stdout, opts)
 	}
+	if config.Sampling.N > 1 {
+		handler = NewSamplingHandler(handler, config.Sampling)
+	}
 
 	return &Logger{
 		slogger: slog.New(handler),
@@ -104,9 +114,23 @@ func New(config LoggerConfig) *Logger {
 	}
 }
 
+// newOutputHandler picks the handler matching the requested output format
+func newOutputHandler(w io.Writer, jsonFormat, otelFormat bool, opts *slog.HandlerOptions) slog.Handler {
+	switch {
+	case otelFormat:
+		return NewOTelHandler(w, opts)
+	case jsonFormat:
+		return slog.NewJSONHandler(w, opts)
+	default:
+		return slog.NewTextHandler(w, opts)
+	}
+}
+
 // getLevelFromString converts LoggerLevel to slog.Level
 func getLevelFromString(level LoggerLevel) slog.Level {
 	switch level {
+	case TraceLevel:
+		return LevelTrace
 	case DebugLevel:
 		return slog.LevelDebug
 	case InfoLevel:
@@ -115,11 +139,35 @@ func getLevelFromString(level LoggerLevel) slog.Level {
 		return slog.LevelWarn
 	case ErrorLevel:
 		return slog.LevelError
+	case FatalLevel:
+		return LevelFatal
 	default:
 		return slog.LevelInfo
 	}
 }
 
+// levelName returns TRACE and FATAL for the custom levels and the slog name otherwise
+func levelName(level slog.Level) string {
+	switch level {
+	case LevelTrace:
+		return "TRACE"
+	case LevelFatal:
+		return "FATAL"
+	default:
+		return level.String()
+	}
+}
+
+// replaceLevel makes the built-in handlers print the names of the custom levels
+func replaceLevel(groups []string, a slog.Attr) slog.Attr {
+	if a.Key == slog.LevelKey && len(groups) == 0 {
+		if level, ok := a.Value.Any().(slog.Level); ok {
+			a.Value = slog.StringValue(levelName(level))
+		}
+	}
+	return a
+}
+
 // MultiHandler implements slog.Handler and writes to multiple handlers
 type MultiHandler struct {
 	handlers []slog.Handler
@@ -181,6 +229,13 @@ func (l *Logger) With(attrs ...any) *Logger {
 	return newLogger
 }
 
+// Trace logs a trace message, below debug, with the given attributes
+func (l *Logger) Trace(msg string, attrs ...any) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	l.slogger.Log(context.Background(), LevelTrace, msg, attrs...)
+}
+
 // Debug logs a debug message with the given attributes
 func (l *Logger) Debug(msg string, attrs ...any) {
 	l.mu.RLock()
@@ -209,6 +264,14 @@ func (l *Logger) Error(msg string, attrs ...any) {
 	l.slogger.Error(msg, attrs...)
 }
 
+// Fatal logs a fatal message with the given attributes and exits the process with status 1
+func (l *Logger) Fatal(msg string, attrs ...any) {
+	l.mu.RLock()
+	l.slogger.Log(context.Background(), LevelFatal, msg, attrs...)
+	l.mu.RUnlock()
+	os.Exit(1)
+}
+
 // UpdateConfig updates the logger configuration dynamically
 func (l *Logger) UpdateConfig(config LoggerConfig) {
 	l.mu.Lock()
@@ -216,8 +279,9 @@ func (l *Logger) UpdateConfig(config LoggerConfig) {
 
 	level := getLevelFromString(config.Level)
 	opts := &slog.HandlerOptions{
-		Level:     level,
-		AddSource: config.AddSource,
+		Level:       level,
+		AddSource:   config.AddSource,
+		ReplaceAttr: replaceLevel,
 	}
 
 	// Create handlers for each output
@@ -225,21 +289,13 @@ func (l *Logger) UpdateConfig(config LoggerConfig) {
 
 	// Main output
 	if config.Output != nil {
-		if config.JSONFormat {
-			handlers = append(handlers, slog.NewJSONHandler(config.Output, opts))
-		} else {
-			handlers = append(handlers, slog.NewTextHandler(config.Output, opts))
-		}
+		handlers = append(handlers, newOutputHandler(config.Output, config.JSONFormat, config.OTelFormat, opts))
 	}
 
 	// Additional outputs
 	for _, outputConfig := range config.AdditionalOutputs {
 		if outputConfig.Writer != nil {
-			if outputConfig.JSONFormat {
-				handlers = append(handlers, slog.NewJSONHandler(outputConfig.Writer, opts))
-			} else {
-				handlers = append(handlers, slog.NewTextHandler(outputConfig.Writer, opts))
-			}
+			handlers = append(handlers, newOutputHandler(outputConfig.Writer, outputConfig.JSONFormat, outputConfig.OTelFormat, opts))
 		}
 	}
 
@@ -253,6 +309,9 @@ func (l *Logger) UpdateConfig(config LoggerConfig) {
 		// Fallback to stdout with text format if no outputs specified
 		handler = slog.NewTextHandler(os.Stdout, opts)
 	}
+	if config.Sampling.N > 1 {
+		handler = NewSamplingHandler(handler, config.Sampling)
+	}
 
 	l.slogger = slog.New(handler)
 	l.config = config
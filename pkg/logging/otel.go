@@ -0,0 +1,163 @@
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// OTelHandler implements slog.Handler and writes one JSON object per record following the OpenTelemetry log data model;
+// attributes are flattened with groups joined by "."
+type OTelHandler struct {
+	w      io.Writer
+	opts   slog.HandlerOptions
+	mu     *sync.Mutex
+	attrs  map[string]any
+	prefix string
+}
+
+type otelRecord struct {
+	Timestamp      string         `json:"timestamp"`
+	SeverityNumber int            `json:"severity_number"`
+	SeverityText   string         `json:"severity_text"`
+	Body           string         `json:"body"`
+	Attributes     map[string]any `json:"attributes,omitempty"`
+}
+
+// NewOTelHandler creates a new OTelHandler that writes to w
+func NewOTelHandler(w io.Writer, opts *slog.HandlerOptions) *OTelHandler {
+	h := &OTelHandler{w: w, mu: new(sync.Mutex), attrs: map[string]any{}}
+	if opts != nil {
+		h.opts = *opts
+	}
+	return h
+}
+
+// Enabled implements slog.Handler.Enabled
+func (h *OTelHandler) Enabled(_ context.Context, level slog.Level) bool {
+	minLevel := slog.LevelInfo
+	if h.opts.Level != nil {
+		minLevel = h.opts.Level.Level()
+	}
+	return level >= minLevel
+}
+
+// Handle implements slog.Handler.Handle
+func (h *OTelHandler) Handle(_ context.Context, r slog.Record) error {
+	attrs := make(map[string]any, len(h.attrs)+r.NumAttrs())
+	for k, v := range h.attrs {
+		attrs[k] = v
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		flattenAttr(attrs, h.prefix, a)
+		return true
+	})
+	if h.opts.AddSource && r.PC != 0 {
+		f, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		attrs["code.filepath"] = f.File
+		attrs["code.lineno"] = f.Line
+		attrs["code.function"] = f.Function
+	}
+	ts := r.Time
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+	rec := otelRecord{
+		Timestamp:      ts.UTC().Format(time.RFC3339Nano),
+		SeverityNumber: otelSeverity(r.Level),
+		SeverityText:   levelName(r.Level),
+		Body:           r.Message,
+		Attributes:     attrs,
+	}
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err = h.w.Write(append(b, '\n'))
+	return err
+}
+
+// WithAttrs implements slog.Handler.WithAttrs
+func (h *OTelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	nh := h.clone()
+	for _, a := range attrs {
+		flattenAttr(nh.attrs, nh.prefix, a)
+	}
+	return nh
+}
+
+// WithGroup implements slog.Handler.WithGroup
+func (h *OTelHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	nh := h.clone()
+	nh.prefix = joinKey(h.prefix, name)
+	return nh
+}
+
+func (h *OTelHandler) clone() *OTelHandler {
+	attrs := make(map[string]any, len(h.attrs))
+	for k, v := range h.attrs {
+		attrs[k] = v
+	}
+	return &OTelHandler{w: h.w, opts: h.opts, mu: h.mu, attrs: attrs, prefix: h.prefix}
+}
+
+// otelSeverity maps a slog level onto the OpenTelemetry severity number range (DEBUG=5, INFO=9, WARN=13, ERROR=17)
+func otelSeverity(level slog.Level) int {
+	n := int(level) + 9
+	switch {
+	case n < 1:
+		return 1
+	case n > 24:
+		return 24
+	default:
+		return n
+	}
+}
+
+func flattenAttr(dst map[string]any, prefix string, a slog.Attr) {
+	v := a.Value.Resolve()
+	if v.Kind() == slog.KindGroup {
+		p := prefix
+		if a.Key != "" {
+			p = joinKey(prefix, a.Key)
+		}
+		for _, ga := range v.Group() {
+			flattenAttr(dst, p, ga)
+		}
+		return
+	}
+	if a.Key == "" {
+		return
+	}
+	key := joinKey(prefix, a.Key)
+	switch v.Kind() {
+	case slog.KindTime:
+		dst[key] = v.Time().Format(time.RFC3339Nano)
+	case slog.KindDuration:
+		dst[key] = v.Duration().String()
+	case slog.KindAny:
+		if err, ok := v.Any().(error); ok {
+			dst[key] = err.Error()
+			return
+		}
+		dst[key] = v.Any()
+	default:
+		dst[key] = v.Any()
+	}
+}
+
+func joinKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
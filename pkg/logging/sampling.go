@@ -0,0 +1,120 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// DefaultSamplingWindow is used when SamplingConfig.Window is not set
+const DefaultSamplingWindow = time.Minute
+
+// SamplingConfig limits repeated messages; records at or below Level with the same message are emitted once every N occurrences
+// within a Window. A zero N disables sampling
+type SamplingConfig struct {
+	Level slog.Level
+	N     int
+	// Window is the period the occurrences are counted over; defaults to DefaultSamplingWindow
+	Window time.Duration
+}
+
+// SamplingHandler implements slog.Handler and drops all but every Nth identical message at or below the configured level.
+// The counts are reset every window; the first record handled after a window ends is preceded by a summary record for every
+// message that had copies suppressed in it, so memory stays bounded by the distinct messages of a single window
+type SamplingHandler struct {
+	handler slog.Handler
+	config  SamplingConfig
+	state   *samplingState
+}
+
+type samplingState struct {
+	mu sync.Mutex
+	// root emits the summaries, free of the attrs and groups added to the derived handlers
+	root      slog.Handler
+	now       func() time.Time
+	windowEnd time.Time
+	counts    map[string]*sampleCount
+}
+
+type sampleCount struct {
+	level slog.Level
+	seen  int
+}
+
+// NewSamplingHandler creates a new SamplingHandler that forwards the sampled records to handler
+func NewSamplingHandler(handler slog.Handler, config SamplingConfig) *SamplingHandler {
+	if config.Window <= 0 {
+		config.Window = DefaultSamplingWindow
+	}
+	return &SamplingHandler{
+		handler: handler,
+		config:  config,
+		state:   &samplingState{root: handler, now: time.Now, counts: make(map[string]*sampleCount)},
+	}
+}
+
+// Enabled implements slog.Handler.Enabled
+func (h *SamplingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+// Handle implements slog.Handler.Handle
+func (h *SamplingHandler) Handle(ctx context.Context, r slog.Record) error {
+	if h.config.N <= 1 || r.Level > h.config.Level {
+		return h.handler.Handle(ctx, r)
+	}
+	now := h.state.now()
+	h.state.mu.Lock()
+	var summaries []slog.Record
+	if !now.Before(h.state.windowEnd) {
+		summaries = h.state.rotate(now, h.config)
+	}
+	c, ok := h.state.counts[r.Message]
+	if !ok {
+		c = &sampleCount{level: r.Level}
+		h.state.counts[r.Message] = c
+	}
+	seen := c.seen
+	c.seen++
+	h.state.mu.Unlock()
+
+	for _, summary := range summaries {
+		if err := h.state.root.Handle(ctx, summary); err != nil {
+			return err
+		}
+	}
+	if seen%h.config.N != 0 {
+		return nil
+	}
+	return h.handler.Handle(ctx, r)
+}
+
+// rotate starts a new window at now and returns the summaries of the one that ended; the caller holds mu
+func (s *samplingState) rotate(now time.Time, config SamplingConfig) []slog.Record {
+	var summaries []slog.Record
+	for _, msg := range slices.Sorted(maps.Keys(s.counts)) {
+		c := s.counts[msg]
+		emitted := (c.seen + config.N - 1) / config.N
+		if suppressed := c.seen - emitted; suppressed > 0 {
+			summary := slog.NewRecord(now, c.level, "suppressed messages", 0)
+			summary.AddAttrs(slog.String("message", msg), slog.Int("count", suppressed), slog.Duration("window", config.Window))
+			summaries = append(summaries, summary)
+		}
+	}
+	clear(s.counts)
+	s.windowEnd = now.Add(config.Window)
+	return summaries
+}
+
+// WithAttrs implements slog.Handler.WithAttrs
+func (h *SamplingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &SamplingHandler{handler: h.handler.WithAttrs(attrs), config: h.config, state: h.state}
+}
+
+// WithGroup implements slog.Handler.WithGroup
+func (h *SamplingHandler) WithGroup(name string) slog.Handler {
+	return &SamplingHandler{handler: h.handler.WithGroup(name), config: h.config, state: h.state}
+}
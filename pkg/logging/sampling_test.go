@@ -0,0 +1,67 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// records drains the JSON lines written to out into their messages; summaries also carry the sampled message and count
+func records(t *testing.T, out *bytes.Buffer) []string {
+	t.Helper()
+	var got []string
+	dec := json.NewDecoder(out)
+	for dec.More() {
+		var r struct {
+			Msg     string `json:"msg"`
+			Message string `json:"message"`
+			Count   int    `json:"count"`
+		}
+		require.NoError(t, dec.Decode(&r))
+		if len(r.Message) > 0 {
+			r.Msg = fmt.Sprintf("%s:%s:%d", r.Msg, r.Message, r.Count)
+		}
+		got = append(got, r.Msg)
+	}
+	return got
+}
+
+func TestSamplingHandler(t *testing.T) {
+	var out bytes.Buffer
+	h := NewSamplingHandler(slog.NewJSONHandler(&out, nil), SamplingConfig{Level: slog.LevelInfo, N: 3, Window: time.Second})
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	h.state.now = func() time.Time { return now }
+	logger := slog.New(h)
+
+	for range 7 {
+		logger.Info("hot")
+	}
+	logger.Warn("hot")
+	logger.With("id", 1).Info("cold")
+	assert.Equal(t, []string{"hot", "hot", "hot", "hot", "cold"}, records(t, &out))
+
+	// the summaries of a window are emitted once it ends and the counts start over
+	now = now.Add(time.Second)
+	logger.Info("hot")
+	assert.Equal(t, []string{"suppressed messages:hot:4", "hot"}, records(t, &out))
+	assert.Len(t, h.state.counts, 1)
+
+	now = now.Add(time.Second)
+	logger.Info("other")
+	assert.Equal(t, []string{"other"}, records(t, &out))
+}
+
+func TestNewSampling(t *testing.T) {
+	var out bytes.Buffer
+	logger := New(LoggerConfig{Level: InfoLevel, JSONFormat: true, Output: &out, Sampling: SamplingConfig{Level: slog.LevelInfo, N: 2}})
+	for range 4 {
+		logger.Info("hot")
+	}
+	assert.Equal(t, []string{"hot", "hot"}, records(t, &out))
+}
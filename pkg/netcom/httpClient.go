@@ -1,14 +1,30 @@
+// Package netcom is the HTTP client of boiler; Client is the single entry point for outbound HTTP requests
 package netcom
 
 import (
+	"bufio"
 	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
 	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"iter"
+	"maps"
+	"mime"
+	"mime/multipart"
 	"net/http"
 	"net/url"
+	"path/filepath"
+	"runtime/debug"
+	"slices"
+	"strings"
 	"time"
 )
 
@@ -18,7 +34,8 @@ type RequestOption func(*http.Request) error
 // ClientOption defines a function that modifies the client
 type ClientOption func(*Client)
 
-// Client represents an HTTP client with configurable options
+// Client represents an HTTP client with configurable options; it is configured once by NewClient and is safe for concurrent use
+// afterwards, provided Headers is not modified while requests are in flight
 type Client struct {
 	baseURL    *url.URL
 	httpClient *http.Client
@@ -28,30 +45,119 @@ type Client struct {
 	   Request headers may overwrite Client headers
 	*/
 	Headers http.Header
+	// methodTimeouts override the client timeout for requests with the given method
+	methodTimeouts map[string]time.Duration
+	// defaultProfile is applied to every request before its own options
+	defaultProfile RequestProfile
+	slowThreshold  time.Duration
+	onSlow         func(RequestInfo)
+	// userAgent is sent with requests that do not set a User-Agent header themselves
+	userAgent string
+	// middlewares wrap the transport in registration order once all options are applied
+	middlewares []Middleware
+	// err records a failed client option; it is returned by NewClientWithError and by every request
+	err error
 }
 
+// RequestInfo describes a completed request reported to the WithSlowThreshold callback
+type RequestInfo struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Duration   time.Duration
+}
+
+// RequestProfile bundles request options that are applied together, e.g. the auth and headers of an internal service
+type RequestProfile []RequestOption
+
 // NewClient creates a new HTTP client with the given options
 func NewClient(options ...ClientOption) *Client {
 	client := &Client{
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
-		Headers: make(http.Header),
+		Headers:   make(http.Header),
+		userAgent: DefaultUserAgent,
 	}
 
 	for _, option := range options {
 		option(client)
 	}
+	if len(client.middlewares) > 0 {
+		rt := client.httpClient.Transport
+		if rt == nil {
+			rt = http.DefaultTransport
+		}
+		for _, mw := range slices.Backward(client.middlewares) {
+			rt = mw(rt)
+		}
+		WithTransport(rt)(client)
+	}
 
 	return client
 }
 
-// WithBaseURL sets the base URL for the client
+// NewClientWithError works like NewClient but returns the error of an option that failed, e.g. WithBaseURL
+func NewClientWithError(options ...ClientOption) (*Client, error) {
+	client := NewClient(options...)
+	if client.err != nil {
+		return nil, client.err
+	}
+	return client, nil
+}
+
+// ErrBadBaseURL is returned for base URLs that cannot be parsed or lack a scheme or host
+var ErrBadBaseURL = errors.New("invalid base URL")
+
+// WithBaseURL sets the base URL for the client; an invalid URL fails NewClientWithError and every request of the client
 func WithBaseURL(baseURL string) ClientOption {
 	return func(c *Client) {
-		if u, err := url.Parse(baseURL); err == nil {
-			c.baseURL = u
+		u, err := url.Parse(baseURL)
+		if err != nil {
+			c.err = fmt.Errorf("%w:%w", ErrBadBaseURL, err)
+			return
+		}
+		if len(u.Scheme) == 0 || len(u.Host) == 0 {
+			c.err = fmt.Errorf("%w:%s is not absolute", ErrBadBaseURL, baseURL)
+			return
+		}
+		c.baseURL = u
+	}
+}
+
+// DefaultUserAgent identifies requests of clients that were not given WithUserAgent as boiler-netcom/<version>, the version being the
+// one of the boiler module the binary was built with
+var DefaultUserAgent = "boiler-netcom/" + moduleVersion()
+
+// moduleVersion returns the version of the boiler module recorded in the build info, or "devel" for local and untagged builds
+func moduleVersion() string {
+	const module = "github.com/ivanehh/boiler"
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "devel"
+	}
+	version := info.Main.Version
+	if info.Main.Path != module {
+		idx := slices.IndexFunc(info.Deps, func(m *debug.Module) bool { return m.Path == module })
+		if idx < 0 {
+			return "devel"
+		}
+		dep := info.Deps[idx]
+		if dep.Replace != nil {
+			dep = dep.Replace
 		}
+		version = dep.Version
+	}
+	if len(version) == 0 || version == "(devel)" {
+		return "devel"
+	}
+	return version
+}
+
+// WithUserAgent sets the User-Agent sent by the client; a User-Agent header set on the client or a request takes precedence
+func WithUserAgent(ua string) ClientOption {
+	return func(c *Client) {
+		c.userAgent = ua
 	}
 }
 
@@ -62,6 +168,26 @@ func WithTimeout(timeout time.Duration) ClientOption {
 	}
 }
 
+// WithMethodTimeout sets a deadline for requests with the given method which replaces the client timeout;
+// a request whose context already carries a deadline, e.g. one supplied through WithContext, is bound by that deadline instead
+func WithMethodTimeout(method string, d time.Duration) ClientOption {
+	return func(c *Client) {
+		if c.methodTimeouts == nil {
+			c.methodTimeouts = make(map[string]time.Duration)
+		}
+		c.methodTimeouts[strings.ToUpper(method)] = d
+	}
+}
+
+// WithSlowThreshold calls onSlow for every request that received a response but took longer than d;
+// the time is measured from building the request until the response headers arrive
+func WithSlowThreshold(d time.Duration, onSlow func(RequestInfo)) ClientOption {
+	return func(c *Client) {
+		c.slowThreshold = d
+		c.onSlow = onSlow
+	}
+}
+
 // WithHTTPClient sets a custom HTTP client
 func WithHTTPClient(httpClient *http.Client) ClientOption {
 	return func(c *Client) {
@@ -69,6 +195,54 @@ func WithHTTPClient(httpClient *http.Client) ClientOption {
 	}
 }
 
+// WithTransport sets the transport used by the client while keeping its other settings such as the timeout
+func WithTransport(rt http.RoundTripper) ClientOption {
+	return func(c *Client) {
+		// copy so that a client passed through WithHTTPClient is not modified
+		hc := *c.httpClient
+		hc.Transport = rt
+		c.httpClient = &hc
+	}
+}
+
+// Middleware wraps the transport of a client, e.g. to log, trace or measure every request it sends
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc lets a function act as an http.RoundTripper when writing a Middleware
+type RoundTripperFunc func(*http.Request) (*http.Response, error)
+
+// RoundTrip implements http.RoundTripper
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// WithMiddleware wraps the client's transport in mw; middlewares see the request once all headers and options are applied
+// and the first one registered is the outermost. They wrap whatever transport the other options leave, regardless of order
+func WithMiddleware(mw Middleware) ClientOption {
+	return func(c *Client) {
+		c.middlewares = append(c.middlewares, mw)
+	}
+}
+
+// WithDefaultProfile applies p to every request of the client; options passed to a request are applied after it
+func WithDefaultProfile(p RequestProfile) ClientOption {
+	return func(c *Client) {
+		c.defaultProfile = p
+	}
+}
+
+// WithProfile applies the options of p in order
+func WithProfile(p RequestProfile) RequestOption {
+	return func(req *http.Request) error {
+		for _, option := range p {
+			if err := option(req); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
 // WithContext adds a context to the request
 func WithContext(ctx context.Context) RequestOption {
 	return func(req *http.Request) error {
@@ -85,9 +259,63 @@ func WithHeader(key, value string) RequestOption {
 	}
 }
 
+// WithBearerToken sets the Authorization header of the request to a bearer token, replacing one set by the client
+func WithBearerToken(token string) RequestOption {
+	return func(req *http.Request) error {
+		req.Header.Set("Authorization", "Bearer "+token)
+		return nil
+	}
+}
+
+// WithBasicAuth sets the Authorization header of the request to basic auth credentials, replacing one set by the client
+func WithBasicAuth(user, pass string) RequestOption {
+	return func(req *http.Request) error {
+		req.SetBasicAuth(user, pass)
+		return nil
+	}
+}
+
+// WithClientBearerToken authorizes every request of the client with a bearer token; see WithBearerToken
+func WithClientBearerToken(token string) ClientOption {
+	return func(c *Client) {
+		c.Headers.Set("Authorization", "Bearer "+token)
+	}
+}
+
+// WithClientBasicAuth authorizes every request of the client with basic auth credentials; see WithBasicAuth
+func WithClientBasicAuth(user, pass string) ClientOption {
+	return func(c *Client) {
+		c.Headers.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(user+":"+pass)))
+	}
+}
+
+// IdempotencyKeyHeader is the header cooperating servers use to deduplicate repeated requests
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// WithIdempotencyKey sets the Idempotency-Key header on the request; an empty key is replaced by a generated one
+/*
+   Build the option once per logical request and reuse it across attempts so every retry carries the same key
+*/
+func WithIdempotencyKey(key string) RequestOption {
+	if len(key) == 0 {
+		key = NewIdempotencyKey()
+	}
+	return func(req *http.Request) error {
+		req.Header.Set(IdempotencyKeyHeader, key)
+		return nil
+	}
+}
+
+// NewIdempotencyKey returns a random 128-bit key encoded as hex
+func NewIdempotencyKey() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
 var ErrBadParameters = errors.New("bad parameters provided")
 
-// WithQueryParam adds a query parameter to the request
+// WithQueryParams adds query parameters to the request from alternating keys and values; repeated keys keep every value
 func WithQueryParams(pairs ...string) RequestOption {
 	return func(req *http.Request) error {
 		if len(pairs)%2 != 0 {
@@ -95,29 +323,64 @@ func WithQueryParams(pairs ...string) RequestOption {
 		}
 
 		q := req.URL.Query()
-		for idx, item := range pairs {
-			if idx == 0 {
-				continue
+		for idx := 0; idx < len(pairs); idx += 2 {
+			q.Add(pairs[idx], pairs[idx+1])
+		}
+		req.URL.RawQuery = q.Encode()
+		return nil
+	}
+}
+
+// withJSONContentType leaves the request with exactly one Content-Type header; a single JSON media type set earlier,
+// e.g. application/merge-patch+json, is kept and anything else is replaced by application/json
+func withJSONContentType() RequestOption {
+	return func(req *http.Request) error {
+		if values := req.Header.Values("Content-Type"); len(values) == 1 {
+			if mt, _, err := mime.ParseMediaType(values[0]); err == nil && (mt == "application/json" || strings.HasSuffix(mt, "+json")) {
+				return nil
 			}
-			if idx%2 == 0 {
-				q.Add(pairs[idx-1], item)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		return nil
+	}
+}
+
+// WithQueryParamsOrdered appends query parameters in the order they are given instead of the sorted order of url.Values.Encode;
+// use it for order sensitive APIs, e.g. signature schemes that hash the exact query string
+func WithQueryParamsOrdered(pairs ...string) RequestOption {
+	return func(req *http.Request) error {
+		if len(pairs)%2 != 0 {
+			return fmt.Errorf("%w:pairs of keys and values must be provided", ErrBadParameters)
+		}
+		var q strings.Builder
+		q.WriteString(req.URL.RawQuery)
+		for idx := 0; idx < len(pairs); idx += 2 {
+			if q.Len() > 0 {
+				q.WriteByte('&')
 			}
+			q.WriteString(url.QueryEscape(pairs[idx]))
+			q.WriteByte('=')
+			q.WriteString(url.QueryEscape(pairs[idx+1]))
 		}
-		req.URL.RawQuery = q.Encode()
+		req.URL.RawQuery = q.String()
 		return nil
 	}
 }
 
-// resolveURL resolves a URL against the base URL
+// resolveURL resolves a URL reference, query included, against the base URL; absolute URLs, e.g. pagination links, are used as they are
 func (c *Client) resolveURL(path string) (*url.URL, error) {
-	if c.baseURL == nil {
-		return url.Parse(path)
+	u, err := url.Parse(path)
+	if err != nil || c.baseURL == nil {
+		return u, err
 	}
-	return c.baseURL.ResolveReference(&url.URL{Path: path}), nil
+	return c.baseURL.ResolveReference(u), nil
 }
 
-// newRequest creates a new HTTP request
-func (c *Client) newRequest(ctx context.Context, method, path string, body io.Reader, options ...RequestOption) (*http.Request, error) {
+// NewRequest builds a request with the base URL, client headers and options applied without sending it
+func (c *Client) NewRequest(ctx context.Context, method, path string, body io.Reader, options ...RequestOption) (*http.Request, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
 	u, err := c.resolveURL(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to resolve URL: %w", err)
@@ -135,12 +398,18 @@ func (c *Client) newRequest(ctx context.Context, method, path string, body io.Re
 		}
 	}
 
-	// Apply request options
+	// Apply the default profile, then the request options
+	if err := WithProfile(c.defaultProfile)(req); err != nil {
+		return nil, fmt.Errorf("failed to apply default profile: %w", err)
+	}
 	for _, option := range options {
 		if err := option(req); err != nil {
 			return nil, fmt.Errorf("failed to apply request option: %w", err)
 		}
 	}
+	if len(req.Header.Values("User-Agent")) == 0 {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
 
 	return req, nil
 }
@@ -156,11 +425,58 @@ func (c *Client) Do(req *http.Request) (*http.Response, error) {
 
 // Request sends an HTTP request with the given method, path, body, and options
 func (c *Client) Request(ctx context.Context, method, path string, body io.Reader, options ...RequestOption) (*http.Response, error) {
-	req, err := c.newRequest(ctx, method, path, body, options...)
+	started := time.Now()
+	var resp *http.Response
+	var err error
+	if d, ok := c.methodTimeouts[strings.ToUpper(method)]; ok {
+		resp, err = c.requestWithTimeout(ctx, d, method, path, body, options...)
+	} else {
+		var req *http.Request
+		if req, err = c.NewRequest(ctx, method, path, body, options...); err != nil {
+			return nil, err
+		}
+		resp, err = c.Do(req)
+	}
+	if err == nil && c.onSlow != nil {
+		if elapsed := time.Since(started); elapsed > c.slowThreshold {
+			c.onSlow(RequestInfo{Method: method, URL: resp.Request.URL.String(), StatusCode: resp.StatusCode, Duration: elapsed})
+		}
+	}
+	return resp, err
+}
+
+// requestWithTimeout bounds the request by d instead of the client timeout unless the caller set a deadline of its own;
+// the deadline is released when the response body is closed
+func (c *Client) requestWithTimeout(ctx context.Context, d time.Duration, method, path string, body io.Reader, options ...RequestOption) (*http.Response, error) {
+	req, err := c.NewRequest(ctx, method, path, body, options...)
 	if err != nil {
 		return nil, err
 	}
-	return c.Do(req)
+	// the context left by the options is checked, so WithContext can extend the timeout but cannot drop it
+	cancel := context.CancelFunc(func() {})
+	if _, ok := req.Context().Deadline(); !ok {
+		ctx, cancel = context.WithTimeout(req.Context(), d)
+		req = req.WithContext(ctx)
+	}
+	hc := *c.httpClient
+	hc.Timeout = 0
+	resp, err := hc.Do(req)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
 }
 
 // Get sends a GET request
@@ -168,6 +484,28 @@ func (c *Client) Get(ctx context.Context, path string, options ...RequestOption)
 	return c.Request(ctx, http.MethodGet, path, nil, options...)
 }
 
+// GetJSON sends a GET request and decodes the JSON response body into v; a non-2xx status is returned as an *HTTPError
+func (c *Client) GetJSON(ctx context.Context, path string, v interface{}, options ...RequestOption) error {
+	resp, err := c.Get(ctx, path, options...)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		bodyBytes, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return fmt.Errorf("failed to read error response body: %w", err)
+		}
+		return &HTTPError{StatusCode: resp.StatusCode, Body: bodyBytes, Header: resp.Header}
+	}
+	body, err := DecodedBody(resp)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	return json.NewDecoder(body).Decode(v)
+}
+
 // Post sends a POST request with the given body
 func (c *Client) Post(ctx context.Context, path string, body io.Reader, options ...RequestOption) (*http.Response, error) {
 	if options == nil {
@@ -183,21 +521,65 @@ func (c *Client) PostJSON(ctx context.Context, path string, data interface{}, op
 		return nil, fmt.Errorf("failed to marshal JSON: %w", err)
 	}
 
-	// Add content-type header if not already present
-	hasContentType := false
-	for _, opt := range options {
-		// This is a simplistic check and might not catch all cases
-		if fmt.Sprintf("%v", opt) == fmt.Sprintf("%v", WithHeader("Content-Type", "application/json")) {
-			hasContentType = true
-			break
-		}
-	}
+	// applied last so that it sees the Content-Type left by the client and the caller's options
+	options = append(options, withJSONContentType())
 
-	if !hasContentType {
-		options = append(options, WithHeader("Content-Type", "application/json"))
+	return c.Post(ctx, path, bytes.NewReader(jsonData), options...)
+}
+
+// PostForm sends values as an application/x-www-form-urlencoded POST request; the Content-Type cannot be replaced by options
+func (c *Client) PostForm(ctx context.Context, path string, values url.Values, options ...RequestOption) (*http.Response, error) {
+	// applied last so that neither the JSON default of Post nor the caller's options change the body's type
+	options = append(options, func(req *http.Request) error {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return nil
+	})
+	return c.Post(ctx, path, strings.NewReader(values.Encode()), options...)
+}
+
+// PostMultipart sends a multipart/form-data POST request with fields followed by files, each in the sorted order of its names;
+// file contents are streamed into the request rather than buffered. A file part is named after the underlying file if the
+// reader has a Name method, e.g. *os.File, and after its field name otherwise; readers implementing io.Closer are not closed
+func (c *Client) PostMultipart(ctx context.Context, path string, fields map[string]string, files map[string]io.Reader, options ...RequestOption) (*http.Response, error) {
+	pr, pw := io.Pipe()
+	mw := multipart.NewWriter(pw)
+	go func() {
+		pw.CloseWithError(writeMultipart(mw, fields, files))
+	}()
+	// applied last so that the boundary of the body cannot be replaced by the caller's options
+	options = append(options, func(req *http.Request) error {
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+		return nil
+	})
+	resp, err := c.Post(ctx, path, pr, options...)
+	if err != nil {
+		// unblocks the writer if the body was never read
+		pr.CloseWithError(err)
+		return nil, err
 	}
+	return resp, nil
+}
 
-	return c.Post(ctx, path, bytes.NewReader(jsonData), options...)
+func writeMultipart(mw *multipart.Writer, fields map[string]string, files map[string]io.Reader) error {
+	for _, name := range slices.Sorted(maps.Keys(fields)) {
+		if err := mw.WriteField(name, fields[name]); err != nil {
+			return err
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		fileName := name
+		if named, ok := files[name].(interface{ Name() string }); ok {
+			fileName = filepath.Base(named.Name())
+		}
+		part, err := mw.CreateFormFile(name, fileName)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(part, files[name]); err != nil {
+			return fmt.Errorf("failed to write file %s:%w", name, err)
+		}
+	}
+	return mw.Close()
 }
 
 // Put sends a PUT request with the given body
@@ -215,33 +597,174 @@ func (c *Client) Patch(ctx context.Context, path string, body io.Reader, options
 	return c.Request(ctx, http.MethodPatch, path, body, options...)
 }
 
-// DecodeResponse decodes the response body into the given value
+// HTTPError is returned when a response carries an unsuccessful status code; Body is the unmodified error body
+type HTTPError struct {
+	StatusCode int
+	Body       []byte
+	Header     http.Header
+}
+
+// APIError is HTTPError under the name used by callers branching on error responses with errors.As
+type APIError = HTTPError
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("request failed with status %d: %s", e.StatusCode, string(e.Body))
+}
+
+// DecodeResponse decodes the response body into the given value; a status of 400 or above is returned as an *APIError
+// the body is drained and closed, so the connection can be reused
 func DecodeResponse(resp *http.Response, v interface{}) error {
-	defer resp.Body.Close()
+	defer func() {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}()
 
 	if resp.StatusCode >= 400 {
 		bodyBytes, err := io.ReadAll(resp.Body)
 		if err != nil {
 			return fmt.Errorf("failed to read error response body: %w", err)
 		}
-		return fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(bodyBytes))
+		return &HTTPError{StatusCode: resp.StatusCode, Body: bodyBytes, Header: resp.Header}
 	}
 
 	if v == nil {
 		return nil
 	}
 
-	return json.NewDecoder(resp.Body).Decode(v)
+	body, err := DecodedBody(resp)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	return json.NewDecoder(body).Decode(v)
+}
+
+// DoJSON sends a request and decodes the JSON response body into a T; the response is returned with its body drained and closed
+// so that its status and headers, e.g. rate limits or an ETag, can still be inspected. A status of 400 or above yields an *APIError
+// along with the response
+func DoJSON[T any](ctx context.Context, c *Client, method, path string, body io.Reader, opts ...RequestOption) (T, *http.Response, error) {
+	var v T
+	resp, err := c.Request(ctx, method, path, body, opts...)
+	if err != nil {
+		return v, nil, err
+	}
+	if err := DecodeResponse(resp, &v); err != nil {
+		return v, resp, err
+	}
+	return v, resp, nil
+}
+
+// Paginate GETs startPath and then every path next returns for the previous page, yielding the page bodies; iteration stops
+// when next returns false or an empty path, on the first error, which is yielded, or when the consumer stops. next may return
+// an absolute URL, e.g. from a Link header, or a reference such as "/items?page=2" or "?cursor=x" resolved against the URL of
+// the previous page. A status of 400 or above yields an *APIError
+func (c *Client) Paginate(ctx context.Context, startPath string, next func(resp *http.Response, body []byte) (string, bool)) iter.Seq2[[]byte, error] {
+	return func(yield func([]byte, error) bool) {
+		path := startPath
+		for {
+			if err := ctx.Err(); err != nil {
+				yield(nil, err)
+				return
+			}
+			resp, body, err := c.getPage(ctx, path)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if !yield(body, nil) {
+				return
+			}
+			nextPath, ok := next(resp, body)
+			if !ok || len(nextPath) == 0 {
+				return
+			}
+			ref, err := url.Parse(nextPath)
+			if err != nil {
+				yield(nil, fmt.Errorf("failed to parse next page URL: %w", err))
+				return
+			}
+			// like a browser following a link, a relative one such as "?cursor=x" is resolved against the page it came from
+			path = resp.Request.URL.ResolveReference(ref).String()
+		}
+	}
+}
+
+func (c *Client) getPage(ctx context.Context, path string) (*http.Response, []byte, error) {
+	resp, err := c.Get(ctx, path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		bodyBytes, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read error response body: %w", err)
+		}
+		return nil, nil, &HTTPError{StatusCode: resp.StatusCode, Body: bodyBytes, Header: resp.Header}
+	}
+	body, err := DecodedBody(resp)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer body.Close()
+	bodyBytes, err := io.ReadAll(body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	return resp, bodyBytes, nil
 }
 
 // ReadResponseBody reads the response body and returns it as a string
 func ReadResponseBody(resp *http.Response) (string, error) {
 	defer resp.Body.Close()
 
-	bodyBytes, err := io.ReadAll(resp.Body)
+	body, err := DecodedBody(resp)
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+	bodyBytes, err := io.ReadAll(body)
 	if err != nil {
 		return "", fmt.Errorf("failed to read response body: %w", err)
 	}
 
 	return string(bodyBytes), nil
 }
+
+// AcceptEncoding is the Accept-Encoding value sent by WithAcceptEncoding; DecodeResponse and ReadResponseBody undo both
+const AcceptEncoding = "gzip, deflate"
+
+// WithAcceptEncoding advertises gzip and deflate support on every request of the client; the transport then leaves
+// compressed bodies as they are and DecodeResponse, ReadResponseBody and DecodedBody decompress them
+func WithAcceptEncoding() ClientOption {
+	return func(c *Client) {
+		c.Headers.Set("Accept-Encoding", AcceptEncoding)
+	}
+}
+
+// DecodedBody wraps the body of resp in a reader undoing its gzip or deflate Content-Encoding, e.g. one requested through
+// WithAcceptEncoding; closing it does not close resp.Body
+func DecodedBody(resp *http.Response) (io.ReadCloser, error) {
+	switch strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding"))) {
+	case "gzip", "x-gzip":
+		zr, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read gzip response body: %w", err)
+		}
+		return zr, nil
+	case "deflate":
+		// deflate should be zlib wrapped, but some servers send the raw stream
+		br := bufio.NewReader(resp.Body)
+		header, err := br.Peek(2)
+		if err == nil && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+			zr, err := zlib.NewReader(br)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read deflate response body: %w", err)
+			}
+			return zr, nil
+		}
+		return flate.NewReader(br), nil
+	default:
+		return io.NopCloser(resp.Body), nil
+	}
+}
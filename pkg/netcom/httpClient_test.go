@@ -0,0 +1,415 @@
+package netcom
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostJSONContentType(t *testing.T) {
+	var got []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Values("Content-Type")
+	}))
+	defer srv.Close()
+
+	cases := map[string]struct {
+		client  []ClientOption
+		options []RequestOption
+		want    string
+	}{
+		"default":          {want: "application/json"},
+		"caller duplicate": {options: []RequestOption{WithHeader("Content-Type", "application/json"), WithHeader("Content-Type", "application/json")}, want: "application/json"},
+		"client and caller": {
+			client:  []ClientOption{func(c *Client) { c.Headers.Add("Content-Type", "text/plain") }},
+			options: []RequestOption{WithHeader("Content-Type", "application/json")},
+			want:    "application/json",
+		},
+		"json variant kept": {options: []RequestOption{WithHeader("Content-Type", "application/merge-patch+json")}, want: "application/merge-patch+json"},
+		"non json replaced": {options: []RequestOption{WithHeader("Content-Type", "text/plain")}, want: "application/json"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := NewClient(append([]ClientOption{WithBaseURL(srv.URL)}, tc.client...)...)
+			resp, err := c.PostJSON(context.Background(), "/", map[string]int{"a": 1}, tc.options...)
+			require.NoError(t, err)
+			resp.Body.Close()
+			assert.Equal(t, []string{tc.want}, got)
+		})
+	}
+}
+
+func TestWithQueryParams(t *testing.T) {
+	cases := map[string]struct {
+		pairs []string
+		want  string
+	}{
+		"one pair":      {pairs: []string{"a", "1"}, want: "a=1"},
+		"many pairs":    {pairs: []string{"b", "2", "a", "1"}, want: "a=1&b=2"},
+		"repeated keys": {pairs: []string{"a", "1", "a", "2"}, want: "a=1&a=2"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			req, err := NewClient().NewRequest(context.Background(), http.MethodGet, "http://example.com/", nil, WithQueryParams(tc.pairs...))
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, req.URL.RawQuery)
+		})
+	}
+	_, err := NewClient().NewRequest(context.Background(), http.MethodGet, "http://example.com/", nil, WithQueryParams("a"))
+	assert.ErrorIs(t, err, ErrBadParameters)
+}
+
+func TestWithBaseURLInvalid(t *testing.T) {
+	for _, base := range []string{"http://exa mple.com", "example.com/api", "://example.com"} {
+		t.Run(base, func(t *testing.T) {
+			_, err := NewClientWithError(WithBaseURL(base))
+			assert.ErrorIs(t, err, ErrBadBaseURL)
+			_, err = NewClient(WithBaseURL(base)).Get(context.Background(), "/path")
+			assert.ErrorIs(t, err, ErrBadBaseURL)
+		})
+	}
+	_, err := NewClientWithError(WithBaseURL("https://example.com/api/"))
+	assert.NoError(t, err)
+}
+
+func TestDecodeResponseCompressed(t *testing.T) {
+	writers := map[string]func(io.Writer) io.WriteCloser{
+		"gzip":    func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) },
+		"deflate": func(w io.Writer) io.WriteCloser { return zlib.NewWriter(w) },
+		"deflate raw": func(w io.Writer) io.WriteCloser {
+			fw, _ := flate.NewWriter(w, flate.DefaultCompression)
+			return fw
+		},
+	}
+	for name, newWriter := range writers {
+		t.Run(name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, AcceptEncoding, r.Header.Get("Accept-Encoding"))
+				w.Header().Set("Content-Encoding", strings.Fields(name)[0])
+				zw := newWriter(w)
+				_, _ = zw.Write([]byte(`{"a":1}`))
+				zw.Close()
+			}))
+			defer srv.Close()
+			c := NewClient(WithBaseURL(srv.URL), WithAcceptEncoding())
+
+			resp, err := c.Get(context.Background(), "/")
+			require.NoError(t, err)
+			var v map[string]int
+			require.NoError(t, DecodeResponse(resp, &v))
+			assert.Equal(t, map[string]int{"a": 1}, v)
+
+			resp, err = c.Get(context.Background(), "/")
+			require.NoError(t, err)
+			body, err := ReadResponseBody(resp)
+			require.NoError(t, err)
+			assert.Equal(t, `{"a":1}`, body)
+		})
+	}
+}
+
+func TestPostMultipart(t *testing.T) {
+	var names []string
+	var contents []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mr, err := r.MultipartReader()
+		require.NoError(t, err)
+		for p, err := mr.NextPart(); err == nil; p, err = mr.NextPart() {
+			b, _ := io.ReadAll(p)
+			names = append(names, p.FormName()+"/"+p.FileName())
+			contents = append(contents, string(b))
+		}
+	}))
+	defer srv.Close()
+
+	resp, err := NewClient(WithBaseURL(srv.URL)).PostMultipart(context.Background(), "/upload",
+		map[string]string{"plant": "sofia", "kind": "daily"},
+		map[string]io.Reader{"report": strings.NewReader("a,b\n1,2"), "attachment": strings.NewReader("note")},
+		WithHeader("Content-Type", "application/json"),
+	)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, []string{"kind/", "plant/", "attachment/attachment", "report/report"}, names)
+	assert.Equal(t, []string{"daily", "sofia", "note", "a,b\n1,2"}, contents)
+}
+
+func TestPostForm(t *testing.T) {
+	var contentType []string
+	var body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Values("Content-Type")
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+	}))
+	defer srv.Close()
+
+	for name, options := range map[string][]RequestOption{"no options": nil, "json header": {WithHeader("Content-Type", "application/json")}} {
+		t.Run(name, func(t *testing.T) {
+			resp, err := NewClient(WithBaseURL(srv.URL)).PostForm(context.Background(), "/", url.Values{"b": {"2 3"}, "a": {"1"}}, options...)
+			require.NoError(t, err)
+			resp.Body.Close()
+			assert.Equal(t, []string{"application/x-www-form-urlencoded"}, contentType)
+			assert.Equal(t, "a=1&b=2+3", body)
+		})
+	}
+}
+
+func TestWithMiddleware(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	defer srv.Close()
+
+	var calls []string
+	record := func(name string) Middleware {
+		return func(next http.RoundTripper) http.RoundTripper {
+			return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				calls = append(calls, name+" "+req.Header.Get("X-Test"))
+				resp, err := next.RoundTrip(req)
+				if err == nil {
+					calls = append(calls, name+" "+resp.Status)
+				}
+				return resp, err
+			})
+		}
+	}
+	c := NewClient(WithBaseURL(srv.URL), WithMiddleware(record("first")), WithMiddleware(record("second")), WithTransport(http.DefaultTransport))
+	resp, err := c.Get(context.Background(), "/", WithHeader("X-Test", "1"))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, []string{"first 1", "second 1", "second 418 I'm a teapot", "first 418 I'm a teapot"}, calls)
+}
+
+func TestDecodeResponseAPIError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "abc")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		_, _ = w.Write([]byte(`{"error":"bad plant"}`))
+	}))
+	defer srv.Close()
+
+	resp, err := NewClient(WithBaseURL(srv.URL)).Get(context.Background(), "/")
+	require.NoError(t, err)
+	err = DecodeResponse(resp, nil)
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusUnprocessableEntity, apiErr.StatusCode)
+	assert.Equal(t, `{"error":"bad plant"}`, string(apiErr.Body))
+	assert.Equal(t, "abc", apiErr.Header.Get("X-Request-Id"))
+	assert.Contains(t, err.Error(), "422")
+}
+
+func TestDoJSON(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"plant":"sofia"}`))
+	}))
+	defer srv.Close()
+	c := NewClient(WithBaseURL(srv.URL))
+
+	type plant struct {
+		Plant string `json:"plant"`
+	}
+	v, resp, err := DoJSON[plant](context.Background(), c, http.MethodGet, "/", nil)
+	require.NoError(t, err)
+	assert.Equal(t, plant{Plant: "sofia"}, v)
+	assert.Equal(t, `"v1"`, resp.Header.Get("ETag"))
+
+	_, resp, err = DoJSON[plant](context.Background(), c, http.MethodGet, "/missing", nil)
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestPaginate(t *testing.T) {
+	var srvURL string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/items":
+			_, _ = w.Write([]byte(`{"next":"/items/2"}`))
+		case "/items/2":
+			w.Header().Set("Link", "<"+srvURL+"/items/3>; rel=\"next\"")
+			_, _ = w.Write([]byte(`{}`))
+		case "/items/3":
+			_, _ = w.Write([]byte(`{"last":true}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	srvURL = srv.URL
+	c := NewClient(WithBaseURL(srv.URL))
+
+	next := func(resp *http.Response, body []byte) (string, bool) {
+		var page struct {
+			Next string `json:"next"`
+		}
+		_ = json.Unmarshal(body, &page)
+		if len(page.Next) > 0 {
+			return page.Next, true
+		}
+		link := resp.Header.Get("Link")
+		if start, end := strings.Index(link, "<"), strings.Index(link, ">"); start >= 0 && end > start {
+			return link[start+1 : end], true
+		}
+		return "", false
+	}
+	var pages []string
+	for body, err := range c.Paginate(context.Background(), "/items", next) {
+		require.NoError(t, err)
+		pages = append(pages, string(body))
+	}
+	assert.Equal(t, []string{`{"next":"/items/2"}`, `{}`, `{"last":true}`}, pages)
+
+	var errs []error
+	for _, err := range c.Paginate(context.Background(), "/missing", next) {
+		errs = append(errs, err)
+	}
+	require.Len(t, errs, 1)
+	var apiErr *APIError
+	assert.ErrorAs(t, errs[0], &apiErr)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, err := range c.Paginate(ctx, "/items", next) {
+		assert.ErrorIs(t, err, context.Canceled)
+	}
+}
+
+func TestPaginateRelativeLinksWithQuery(t *testing.T) {
+	var got []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.URL.RequestURI())
+		switch r.URL.RequestURI() {
+		case "/items":
+			_, _ = w.Write([]byte("/items?page=2"))
+		case "/items?page=2":
+			_, _ = w.Write([]byte("?cursor=x"))
+		default:
+			_, _ = w.Write(nil)
+		}
+	}))
+	defer srv.Close()
+	c := NewClient(WithBaseURL(srv.URL))
+
+	next := func(_ *http.Response, body []byte) (string, bool) {
+		return string(body), len(body) > 0
+	}
+	for _, err := range c.Paginate(context.Background(), "/items", next) {
+		require.NoError(t, err)
+	}
+	assert.Equal(t, []string{"/items", "/items?page=2", "/items?cursor=x"}, got)
+}
+
+func TestUserAgent(t *testing.T) {
+	var got []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Values("User-Agent")
+	}))
+	defer srv.Close()
+
+	cases := map[string]struct {
+		client  []ClientOption
+		options []RequestOption
+		want    string
+	}{
+		"default":          {want: "boiler-netcom/devel"},
+		"client option":    {client: []ClientOption{WithUserAgent("plant-sync/2")}, want: "plant-sync/2"},
+		"request override": {client: []ClientOption{WithUserAgent("plant-sync/2")}, options: []RequestOption{WithHeader("User-Agent", "probe")}, want: "probe"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			resp, err := NewClient(append([]ClientOption{WithBaseURL(srv.URL)}, tc.client...)...).Get(context.Background(), "/", tc.options...)
+			require.NoError(t, err)
+			resp.Body.Close()
+			assert.Equal(t, []string{tc.want}, got)
+		})
+	}
+}
+
+func TestContextErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	c := NewClient(WithBaseURL(srv.URL))
+
+	expired, cancelExpired := context.WithDeadline(context.Background(), time.Now())
+	defer cancelExpired()
+	_, err := c.Get(expired, "/")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = c.Get(canceled, "/")
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestMethodTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(200 * time.Millisecond):
+		}
+	}))
+	defer srv.Close()
+	c := NewClient(WithBaseURL(srv.URL), WithMethodTimeout("get", 50*time.Millisecond))
+
+	// a context without a deadline passed through WithContext must not lift the method timeout
+	_, err := c.Get(context.Background(), "/", WithContext(context.Background()))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// the method is matched regardless of case
+	_, err = c.Request(context.Background(), "get", "/", nil)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// a deadline set by the caller wins over the method timeout, even a longer one
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	resp, err := c.Get(context.Background(), "/", WithContext(ctx))
+	require.NoError(t, err)
+	resp.Body.Close()
+	resp, err = c.Get(ctx, "/")
+	require.NoError(t, err)
+	resp.Body.Close()
+}
+
+func TestClientConcurrentRequests(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.URL.Query().Get("n")))
+	}))
+	defer srv.Close()
+	c := NewClient(WithBaseURL(srv.URL), WithClientBearerToken("t"), WithDefaultProfile(RequestProfile{WithHeader("X-Test", "1")}))
+
+	var wg sync.WaitGroup
+	for n := range 16 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := c.Get(context.Background(), "/", WithQueryParams("n", strconv.Itoa(n)))
+			if !assert.NoError(t, err) {
+				return
+			}
+			body, err := ReadResponseBody(resp)
+			assert.NoError(t, err)
+			assert.Equal(t, strconv.Itoa(n), body)
+		}()
+	}
+	wg.Wait()
+}